@@ -56,78 +56,106 @@ func (r RegexpMap) LookupDefault(s, def string) string {
 
 // MustLoadRegexpMap loads the content of a given asset path into a RegexpMap. It
 // will panic, if the asset path is not found and if the patterns found in the
-// file cannot be compiled.
-func MustLoadRegexpMap(ap string) RegexpMap {
-	b, err := Asset(ap)
-	if err != nil {
-		panic(err)
-	}
-	d := make(map[string]string)
-	err = json.Unmarshal(b, &d)
-	if err != nil {
-		panic(err)
-	}
-	remap := RegexpMap{}
-	for k, v := range d {
-		remap.Entries = append(remap.Entries, RegexpMapEntry{Pattern: regexp.MustCompile(k), Value: v})
-	}
+// file cannot be compiled. The map is updated in place, if assets are
+// reloaded.
+func MustLoadRegexpMap(ap string) *RegexpMap {
+	remap := &RegexpMap{}
+	mustLoad(ap, func(b []byte) error {
+		d := make(map[string]string)
+		if err := json.Unmarshal(b, &d); err != nil {
+			return err
+		}
+		var entries []RegexpMapEntry
+		for k, v := range d {
+			re, err := regexp.Compile(k)
+			if err != nil {
+				return err
+			}
+			entries = append(entries, RegexpMapEntry{Pattern: re, Value: v})
+		}
+		remap.Entries = entries
+		return nil
+	})
 	return remap
 }
 
+// MustLoadStringSet reads lines from a number of assets into a set. Empty
+// lines are ignored.
 func MustLoadStringSet(paths ...string) *container.StringSet {
 	s := container.NewStringSet()
-	for _, path := range paths {
-		b, err := Asset(path)
-		if err != nil {
-			panic(err)
-		}
-		rdr := bufio.NewReader(bytes.NewReader(b))
-		for {
-			line, err := rdr.ReadString('\n')
-			if err == io.EOF {
-				break
+	lines := make([][]string, len(paths))
+	for i, path := range paths {
+		i := i
+		mustLoad(path, func(b []byte) error {
+			lines[i] = nil
+			rdr := bufio.NewReader(bytes.NewReader(b))
+			for {
+				line, err := rdr.ReadString('\n')
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					return err
+				}
+				line = strings.TrimSpace(line)
+				if line == "" {
+					continue
+				}
+				lines[i] = append(lines[i], line)
 			}
-			if err != nil {
-				panic(err)
+			// Rebuild the set, since only a single asset might have changed.
+			s.Set = make(map[string]struct{})
+			for _, l := range lines {
+				for _, line := range l {
+					s.Add(line)
+				}
 			}
-			line = strings.TrimSpace(line)
-			if line == "" {
-				continue
-			}
-			s.Add(line)
-		}
+			return nil
+		})
 	}
 	return s
 }
 
 // MustLoadStringMap loads a JSON file from an asset path and parses it into a
 // container.StringMap. This function will panic, if the asset cannot be found
-// or the JSON is erroneous.
+// or the JSON is erroneous. The map is updated in place, if assets are
+// reloaded.
 func MustLoadStringMap(path string) container.StringMap {
-	b, err := Asset(path)
-	if err != nil {
-		panic(err)
-	}
-	d := make(map[string]string)
-	err = json.Unmarshal(b, &d)
-	if err != nil {
-		panic(err)
-	}
-	return container.StringMap(d)
+	m := make(container.StringMap)
+	mustLoad(path, func(b []byte) error {
+		d := make(map[string]string)
+		if err := json.Unmarshal(b, &d); err != nil {
+			return err
+		}
+		for k := range m {
+			delete(m, k)
+		}
+		for k, v := range d {
+			m[k] = v
+		}
+		return nil
+	})
+	return m
 }
 
 // MustLoadStringSliceMap loads a JSON file from an asset path and parses it into
 // a container.StringSliceMap. This function will halt the world, if it is
-// called with an invalid argument.
+// called with an invalid argument. The map is updated in place, if assets are
+// reloaded.
 func MustLoadStringSliceMap(path string) container.StringSliceMap {
-	b, err := Asset(path)
-	if err != nil {
-		panic(err)
-	}
-	d := make(map[string][]string)
-	err = json.Unmarshal(b, &d)
-	if err != nil {
-		panic(err)
-	}
-	return container.StringSliceMap(d)
+	m := make(container.StringSliceMap)
+	mustLoad(path, func(b []byte) error {
+		d := make(map[string][]string)
+		if err := json.Unmarshal(b, &d); err != nil {
+			return err
+		}
+		for k := range m {
+			delete(m, k)
+		}
+		for k, v := range d {
+			m[k] = v
+		}
+		return nil
+	})
+	return m
 }
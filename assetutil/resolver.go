@@ -0,0 +1,219 @@
+package assetutil
+
+import (
+	"crypto/sha1"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/miku/span"
+	log "github.com/sirupsen/logrus"
+)
+
+// SearchPath lists directories with assets, that take precedence over the
+// embedded copies. Assets are looked up by their path without the leading
+// "assets/", e.g. ~/.config/span/maps/finc/formats/de15.json for the asset
+// assets/finc/formats/de15.json.
+var SearchPath = []string{
+	filepath.Join(span.UserHomeDir(), ".config", "span", "maps"),
+	"/etc/span/maps",
+}
+
+// Embedded is the origin of assets compiled into the binary.
+const Embedded = "embedded"
+
+// Info describes, which version of an asset is in use.
+type Info struct {
+	Path   string // Asset path, e.g. assets/finc/formats/de15.json.
+	Origin string // File, merged file or Embedded.
+	SHA1   string // Checksum of the content.
+}
+
+// resolver keeps the explicitly configured asset location and all loaded
+// assets, so they can be reloaded, if the location changes.
+type resolver struct {
+	mu       sync.Mutex
+	explicit string                 // Directory or merged JSON file.
+	merged   map[string]interface{} // Parsed merged file, if any.
+	loaded   map[string]Info
+	fillers  map[string][]func([]byte) error
+}
+
+var defaultResolver = &resolver{
+	loaded:  make(map[string]Info),
+	fillers: make(map[string][]func([]byte) error),
+}
+
+// relpath strips the assets prefix from an asset path.
+func relpath(path string) string {
+	return strings.TrimPrefix(path, "assets/")
+}
+
+// lookupMerged finds an asset in a merged file, as created by
+// span-join-assets. The keys are the path segments of the asset, without
+// file extension, optionally nested under a top level "assets" key.
+func lookupMerged(doc map[string]interface{}, path string) ([]byte, bool) {
+	parts := strings.Split(relpath(path), "/")
+	last := len(parts) - 1
+	parts[last] = strings.Split(parts[last], ".")[0]
+	for _, root := range []interface{}{doc["assets"], doc} {
+		v := root
+		for _, p := range parts {
+			m, ok := v.(map[string]interface{})
+			if !ok {
+				v = nil
+				break
+			}
+			v = m[p]
+		}
+		switch t := v.(type) {
+		case nil:
+			continue
+		case []interface{}:
+			// Single column TSV, turn into lines again.
+			var lines []string
+			for _, item := range t {
+				lines = append(lines, fmt.Sprintf("%v", item))
+			}
+			return []byte(strings.Join(lines, "\n") + "\n"), true
+		default:
+			b, err := json.Marshal(t)
+			if err != nil {
+				return nil, false
+			}
+			return b, true
+		}
+	}
+	return nil, false
+}
+
+// resolve returns the content of an asset and its origin. The explicit
+// location is tried first, then the search path, then the embedded copy.
+func (r *resolver) resolve(path string) ([]byte, string, error) {
+	if r.merged != nil {
+		if b, ok := lookupMerged(r.merged, path); ok {
+			return b, fmt.Sprintf("%s#%s", r.explicit, relpath(path)), nil
+		}
+	}
+	var dirs []string
+	if r.explicit != "" && r.merged == nil {
+		dirs = append(dirs, r.explicit)
+	}
+	dirs = append(dirs, SearchPath...)
+	for _, dir := range dirs {
+		filename := filepath.Join(dir, relpath(path))
+		b, err := ioutil.ReadFile(filename)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		return b, filename, nil
+	}
+	b, err := Asset(path)
+	return b, Embedded, err
+}
+
+// load resolves an asset and passes its content to fill. The fill function
+// is kept, so the asset can be reloaded later.
+func (r *resolver) load(path string, fill func([]byte) error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.fill(path, fill); err != nil {
+		return err
+	}
+	r.fillers[path] = append(r.fillers[path], fill)
+	return nil
+}
+
+// fill resolves a single asset, calls fill and records the version.
+func (r *resolver) fill(path string, fill func([]byte) error) error {
+	b, origin, err := r.resolve(path)
+	if err != nil {
+		return err
+	}
+	if err := fill(b); err != nil {
+		return fmt.Errorf("%s: %v", origin, err)
+	}
+	info := Info{Path: path, Origin: origin, SHA1: fmt.Sprintf("%x", sha1.Sum(b))}
+	if r.loaded[path] != info {
+		if origin == Embedded {
+			log.Debugf("[assets] %s: %s (%0.8s)", path, origin, info.SHA1)
+		} else {
+			log.Printf("[assets] %s: %s (%0.8s)", path, origin, info.SHA1)
+		}
+	}
+	r.loaded[path] = info
+	return nil
+}
+
+// SetAssets sets an explicit asset location, either a directory or a single
+// JSON file, as created by span-join-assets. It takes precedence over the
+// search path. All assets loaded so far are reloaded.
+func SetAssets(location string) error {
+	r := defaultResolver
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	fi, err := os.Stat(location)
+	if err != nil {
+		return err
+	}
+	r.explicit, r.merged = location, nil
+	if !fi.IsDir() {
+		b, err := ioutil.ReadFile(location)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(b, &r.merged); err != nil {
+			return fmt.Errorf("%s: %v", location, err)
+		}
+	}
+	for path, fills := range r.fillers {
+		for _, fill := range fills {
+			if err := r.fill(path, fill); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Load returns the content of an asset, looked up in the explicit location,
+// the search path and the embedded assets, in that order.
+func Load(path string) ([]byte, error) {
+	r := defaultResolver
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var result []byte
+	err := r.fill(path, func(b []byte) error {
+		result = b
+		return nil
+	})
+	return result, err
+}
+
+// Loaded returns information about all assets loaded so far, sorted by path.
+func Loaded() []Info {
+	r := defaultResolver
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var result []Info
+	for _, info := range r.loaded {
+		result = append(result, info)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Path < result[j].Path })
+	return result
+}
+
+// mustLoad loads an asset or panics.
+func mustLoad(path string, fill func([]byte) error) {
+	if err := defaultResolver.load(path, fill); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,86 @@
+package assetutil
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLookupMerged(t *testing.T) {
+	doc := map[string]interface{}{
+		"assets": map[string]interface{}{
+			"genios": map[string]interface{}{
+				"dbmap": map[string]interface{}{"ABC": []interface{}{"X"}},
+			},
+			"qc": map[string]interface{}{
+				"collections": map[string]interface{}{
+					"crossref": []interface{}{"a", "b"},
+				},
+			},
+		},
+	}
+	var tests = []struct {
+		path   string
+		result string
+		ok     bool
+	}{
+		{"assets/genios/dbmap.json", `{"ABC":["X"]}`, true},
+		{"assets/qc/collections/crossref.tsv", "a\nb\n", true},
+		{"assets/genios/missing.json", "", false},
+	}
+	for _, c := range tests {
+		b, ok := lookupMerged(doc, c.path)
+		if ok != c.ok || string(b) != c.result {
+			t.Errorf("lookupMerged(%s): got %q, %v, want %q, %v", c.path, b, ok, c.result, c.ok)
+		}
+	}
+}
+
+func TestSetAssets(t *testing.T) {
+	dir, err := ioutil.TempDir("", "span-assets-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Isolate from local configuration.
+	saved := SearchPath
+	SearchPath = []string{dir}
+	defer func() {
+		SearchPath = saved
+		defaultResolver.explicit, defaultResolver.merged = "", nil
+	}()
+
+	const path = "assets/test/example.json"
+	if err := os.MkdirAll(filepath.Join(dir, "test"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "test", "example.json"), []byte(`{"a": "1"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m := MustLoadStringMap(path)
+	if m["a"] != "1" {
+		t.Fatalf("got %v, want a=1", m)
+	}
+
+	override := filepath.Join(dir, "merged.json")
+	if err := ioutil.WriteFile(override, []byte(`{"test": {"example": {"b": "2"}}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetAssets(override); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := m["a"]; ok || m["b"] != "2" {
+		t.Errorf("after reload: got %v, want b=2", m)
+	}
+	var found bool
+	for _, info := range Loaded() {
+		if info.Path == path {
+			found = info.Origin == override+"#test/example.json"
+		}
+	}
+	if !found {
+		t.Errorf("expected merged file as origin in %v", Loaded())
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"runtime"
 	"sync/atomic"
@@ -34,7 +35,7 @@ func main() {
 
 	errStats := make(map[string]*int64)
 
-	p := parallel.NewProcessor(bufio.NewReader(os.Stdin), os.Stdout, func(_ int64, b []byte) ([]byte, error) {
+	transform := func(_ int64, b []byte) ([]byte, error) {
 		var is finc.IntermediateSchema
 		if err := json.Unmarshal(b, &is); err != nil {
 			return b, err
@@ -57,12 +58,16 @@ func main() {
 			}
 		}
 		return nil, nil
-	})
-
-	p.NumWorkers = *numWorkers
-	p.BatchSize = *size
+	}
 
-	if err := p.Run(); err != nil {
+	// Process members one by one, so errors carry the file or member name.
+	err := span.WalkInput(flag.Args(), true, func(_ string, r io.Reader) error {
+		p := parallel.NewProcessor(bufio.NewReader(r), os.Stdout, transform)
+		p.NumWorkers = *numWorkers
+		p.BatchSize = *size
+		return p.Run()
+	})
+	if err != nil {
 		log.Fatal(err)
 	}
 
@@ -22,7 +22,6 @@ import (
 	"runtime/pprof"
 	"strings"
 
-	gzip "github.com/klauspost/pgzip"
 	"github.com/miku/clam"
 	"github.com/miku/span"
 	"github.com/miku/span/formats/crossref"
@@ -59,7 +58,7 @@ func WriteFields(w io.Writer, values ...interface{}) (int, error) {
 func main() {
 	excludeFile := flag.String("x", "", "a list of DOI to further ignore")
 	outputFile := flag.String("o", "", "output file")
-	compressed := flag.Bool("z", false, "input is gzip compressed")
+	compressed := flag.Bool("z", false, "input is gzip compressed (detected automatically)")
 	batchsize := flag.Int("b", 40000, "batch size")
 	cpuProfile := flag.String("cpuprofile", "", "write cpuprofile to file")
 	verbose := flag.Bool("verbose", false, "be verbose")
@@ -86,23 +85,22 @@ func main() {
 		log.Fatal("output filename required")
 	}
 
-	var reader io.Reader
-
 	f, err := os.Open(flag.Arg(0))
 	if err != nil {
 		log.Fatal(err)
 	}
 	defer f.Close()
-	reader = f
 
-	if *compressed {
-		g, err := gzip.NewReader(f)
-		if err != nil {
-			log.Fatal(err)
-		}
-		defer g.Close()
-		reader = g
+	// Compression is detected, -z is kept for compatibility. Stage 3 works
+	// on the original file and only supports gzip.
+	reader, compression, err := span.Decompress(f)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if compression != "" && compression != "gzip" {
+		log.Fatalf("unsupported compression: %s", compression)
 	}
+	*compressed = compression == "gzip"
 
 	excludes := make(map[string]struct{})
 
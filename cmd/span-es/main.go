@@ -0,0 +1,89 @@
+// span-es loads documents into Elasticsearch or OpenSearch and generates
+// index templates from export mappings.
+//
+//     $ span-export -o bulk -index ai-2017 file.is > file.ndjson
+//     $ span-es -template -index ai-2017 > template.json
+//     $ span-es -server http://localhost:9200 -put-template -index ai-2017
+//     $ span-es -server http://localhost:9200 file.ndjson
+//
+// Documents are sent in batches, requests and items rejected by a busy
+// server are retried.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/miku/span"
+	"github.com/miku/span/esutil"
+	"github.com/miku/span/formats/finc"
+	log "github.com/sirupsen/logrus"
+)
+
+func main() {
+	server := flag.String("server", "http://localhost:9200", "Elasticsearch or OpenSearch server")
+	index := flag.String("index", "", "index name, used as default index for bulk requests and as template pattern")
+	mappingName := flag.String("mapping", "solr5vu3", "built-in mapping or mapping file, the template is derived from")
+	showTemplate := flag.Bool("template", false, "print index template derived from mapping")
+	putTemplate := flag.Bool("put-template", false, "install index template derived from mapping, named after the index")
+	size := flag.Int("b", 1000, "number of actions per bulk request")
+	retries := flag.Int("retries", 5, "number of retries per request")
+	backoff := flag.Duration("backoff", 2*time.Second, "initial delay between retries")
+	verbose := flag.Bool("verbose", false, "be verbose")
+	showVersion := flag.Bool("v", false, "prints current program version")
+
+	flag.Parse()
+
+	if *showVersion {
+		fmt.Println(span.AppVersion)
+		os.Exit(0)
+	}
+	if *verbose {
+		log.SetLevel(log.DebugLevel)
+	}
+
+	l := esutil.NewLoader(*server)
+	l.Index = *index
+	l.BatchSize = *size
+	l.MaxRetries = *retries
+	l.Backoff = *backoff
+
+	if *showTemplate || *putTemplate {
+		if *index == "" {
+			log.Fatal("index name required for template")
+		}
+		mapping, err := finc.LoadMapping(*mappingName)
+		if err != nil {
+			log.Fatal(err)
+		}
+		t := esutil.NewTemplate(mapping, *index+"*")
+		if *showTemplate {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "    ")
+			if err := enc.Encode(t); err != nil {
+				log.Fatal(err)
+			}
+			os.Exit(0)
+		}
+		if err := l.PutTemplate(*index, t); err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("installed template %s", *index)
+		os.Exit(0)
+	}
+
+	reader := span.OpenInput(flag.Args()...)
+	defer reader.Close()
+
+	started := time.Now()
+	if err := l.Load(reader); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("%d documents indexed, %d failed in %s", l.Indexed, l.Failed, time.Since(started))
+	if l.Failed > 0 {
+		os.Exit(1)
+	}
+}
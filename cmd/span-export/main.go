@@ -2,6 +2,7 @@
 package main
 
 import (
+	"bufio"
 	"encoding/json"
 	"flag"
 	"fmt"
@@ -15,24 +16,46 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	"github.com/miku/span"
+	"github.com/miku/span/assetutil"
 	"github.com/miku/span/formats/finc"
+	"github.com/miku/span/openurl"
 	"github.com/miku/span/parallel"
 )
 
 // Exporters holds available export formats
 var Exporters = map[string]func() finc.Exporter{
-	"solr5vu3": func() finc.Exporter { return new(finc.Solr5Vufind3) },
 	"formeta":  func() finc.Exporter { return new(finc.Formeta) },
+	"ris":      func() finc.Exporter { return new(finc.RIS) },
+	"bibtex":   func() finc.Exporter { return new(finc.BibTeX) },
+	"csl-json": func() finc.Exporter { return new(finc.CSLJSON) },
+	"marcxml":  func() finc.Exporter { return new(finc.MarcXML) },
+	"marc21":   func() finc.Exporter { return new(finc.Marc21) },
+	"openurl":  func() finc.Exporter { return &finc.OpenURL{Resolvers: resolvers} },
+	"coins":    func() finc.Exporter { return new(finc.COinS) },
 }
 
+// resolvers are link resolver base URLs by ISIL for OpenURL export.
+var resolvers openurl.Resolvers
+
+// Mappings are the built-in declarative export formats, any other mapping
+// file can be passed to -o as well, see finc.Mapping. The bulk format wraps
+// a mapping for Elasticsearch and OpenSearch.
+var Mappings = []string{"solr5vu3", "bulk"}
+
 func main() {
 	showVersion := flag.Bool("v", false, "prints current program version")
 	size := flag.Int("b", 20000, "batch size")
 	numWorkers := flag.Int("w", runtime.NumCPU(), "number of workers")
 	cpuprofile := flag.String("cpuprofile", "", "write cpu profile to file")
-	format := flag.String("o", "solr5vu3", "output format")
+	format := flag.String("o", "solr5vu3", "output format or mapping file")
 	listFormats := flag.Bool("list", false, "list output formats")
 	withFullrecord := flag.Bool("with-fullrecord", false, "populate fullrecord field with originating intermediate schema record")
+	assets := flag.String("assets", "", "directory or single JSON file with asset maps, overriding the embedded ones")
+	verbose := flag.Bool("verbose", false, "log the origin of each asset map")
+	index := flag.String("index", "", "index name for bulk actions, optional")
+	idField := flag.String("id-field", "finc.id", "intermediate schema field used as document id in bulk actions")
+	bulkMapping := flag.String("mapping", "solr5vu3", "built-in mapping or mapping file for bulk documents")
+	resolversFile := flag.String("resolvers", "", "JSON file with link resolver base URLs by ISIL for OpenURL export, empty key for a default")
 
 	flag.Parse()
 
@@ -46,11 +69,30 @@ func main() {
 		for key := range Exporters {
 			keys = append(keys, key)
 		}
+		keys = append(keys, Mappings...)
 		sort.Strings(keys)
 		fmt.Println(strings.Join(keys, "\n"))
 		os.Exit(0)
 	}
 
+	if *assets != "" {
+		if err := assetutil.SetAssets(*assets); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if *verbose {
+		for _, info := range assetutil.Loaded() {
+			log.Printf("%s: %s (%0.8s)", info.Path, info.Origin, info.SHA1)
+		}
+	}
+
+	if *resolversFile != "" {
+		var err error
+		if resolvers, err = openurl.LoadResolvers(*resolversFile); err != nil {
+			log.Fatal(err)
+		}
+	}
+
 	if *cpuprofile != "" {
 		f, err := os.Create(*cpuprofile)
 		if err != nil {
@@ -66,26 +108,24 @@ func main() {
 	}
 
 	exportSchemaFunc, ok := Exporters[*format]
-	if !ok {
-		log.Fatalf("unknown export schema: %s", *format)
-	}
-
-	var reader io.Reader = os.Stdin
-
-	if flag.NArg() > 0 {
-		var files []io.Reader
-		for _, filename := range flag.Args() {
-			f, err := os.Open(filename)
-			if err != nil {
-				log.Fatal(err)
-			}
-			defer f.Close()
-			files = append(files, f)
+	switch {
+	case *format == "bulk":
+		mapping, err := finc.LoadMapping(*bulkMapping)
+		if err != nil {
+			log.Fatal(err)
+		}
+		bulk := &finc.Bulk{Index: *index, IDField: *idField, Mapping: mapping}
+		exportSchemaFunc = func() finc.Exporter { return bulk }
+	case !ok:
+		// A built-in mapping or a mapping file, shared by all workers.
+		mapping, err := finc.LoadMapping(*format)
+		if err != nil {
+			log.Fatalf("unknown export schema: %s: %v", *format, err)
 		}
-		reader = io.MultiReader(files...)
+		exportSchemaFunc = func() finc.Exporter { return mapping }
 	}
 
-	p := parallel.NewProcessor(reader, os.Stdout, func(_ int64, b []byte) ([]byte, error) {
+	transform := func(_ int64, b []byte) ([]byte, error) {
 		is := finc.IntermediateSchema{}
 
 		// TODO(miku): Unmarshal date correctly.
@@ -98,19 +138,30 @@ func main() {
 		schema := exportSchemaFunc()
 
 		bb, err := schema.Export(is, *withFullrecord)
+		if _, ok := err.(span.Skip); ok {
+			log.Printf("%s: %v", is.ID, err)
+			return nil, nil
+		}
 		if err != nil {
 			log.Printf("failed to convert: %v", is)
 			return bb, err
 		}
 
+		if sd, ok := schema.(finc.SelfDelimiter); ok && sd.SelfDelimiting() {
+			return bb, nil
+		}
 		bb = append(bb, '\n')
 		return bb, nil
-	})
-
-	p.NumWorkers = *numWorkers
-	p.BatchSize = *size
+	}
 
-	if err := p.Run(); err != nil {
+	// Process members one by one, so errors carry the file or member name.
+	err := span.WalkInput(flag.Args(), true, func(_ string, r io.Reader) error {
+		p := parallel.NewProcessor(bufio.NewReader(r), os.Stdout, transform)
+		p.NumWorkers = *numWorkers
+		p.BatchSize = *size
+		return p.Run()
+	})
+	if err != nil {
 		log.Fatal(err)
 	}
 }
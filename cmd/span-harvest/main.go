@@ -0,0 +1,100 @@
+// span-harvest harvests records from an OAI-PMH endpoint and writes the raw
+// responses as a single XML stream, suitable for span-import.
+//
+//     $ span-harvest -endpoint http://www.ssoar.info/OAIHandler/request \
+//         -prefix oai_dc -from 2017-01-01 -o ssoar.xml
+//     $ span-import -i ssoar ssoar.xml
+//
+// Progress is saved to a state file after each page (by default the output
+// filename with a .state suffix). Running the same command again after an
+// interruption continues with the last resumption token and appends to the
+// output file, after removing a page, that was written but not saved.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/miku/span"
+	"github.com/miku/span/oai"
+	log "github.com/sirupsen/logrus"
+)
+
+func main() {
+	endpoint := flag.String("endpoint", "", "OAI-PMH endpoint URL, may be given as argument as well")
+	prefix := flag.String("prefix", "oai_dc", "metadata prefix")
+	set := flag.String("set", "", "set spec for selective harvesting")
+	from := flag.String("from", "", "harvest records changed on or after this date")
+	until := flag.String("until", "", "harvest records changed on or before this date")
+	outputFile := flag.String("o", "", "output file, required for resumable harvests, defaults to stdout")
+	stateFile := flag.String("state", "", "state file, defaults to output file with .state suffix")
+	retries := flag.Int("retries", 5, "number of retries per request")
+	backoff := flag.Duration("backoff", 2*time.Second, "initial delay between retries")
+	verbose := flag.Bool("verbose", false, "be verbose")
+	showVersion := flag.Bool("v", false, "prints current program version")
+
+	flag.Parse()
+
+	if *showVersion {
+		fmt.Println(span.AppVersion)
+		os.Exit(0)
+	}
+	if *verbose {
+		log.SetLevel(log.DebugLevel)
+	}
+	if *endpoint == "" && flag.NArg() > 0 {
+		*endpoint = flag.Arg(0)
+	}
+	if *endpoint == "" {
+		log.Fatal("endpoint required")
+	}
+
+	h := oai.NewHarvester(*endpoint, *prefix)
+	h.Set = *set
+	h.From = *from
+	h.Until = *until
+	h.MaxRetries = *retries
+	h.Backoff = *backoff
+
+	var (
+		w io.Writer = os.Stdout
+		f *os.File
+	)
+	if *outputFile != "" {
+		h.StateFile = *stateFile
+		if h.StateFile == "" {
+			h.StateFile = *outputFile + ".state"
+		}
+		if _, err := os.Stat(h.StateFile); os.IsNotExist(err) {
+			// A new harvest starts with an empty output file.
+			if err := os.Remove(*outputFile); err != nil && !os.IsNotExist(err) {
+				log.Fatal(err)
+			}
+		}
+		var err error
+		f, err = os.OpenFile(*outputFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			log.Fatal(err)
+		}
+		w = f
+	} else if *stateFile != "" {
+		log.Fatal("state file requires an output file (-o)")
+	}
+
+	started := time.Now()
+	err := h.Run(w)
+	if f != nil {
+		// Close explicitly, log.Fatal skips deferred calls.
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	state := h.State()
+	log.Printf("harvested %d pages, %d records in %s", state.Pages, state.Records, time.Since(started))
+}
@@ -2,134 +2,312 @@
 package main
 
 import (
+	"bytes"
 	"encoding"
 	"encoding/json"
+	"encoding/xml"
 	"flag"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
+	"reflect"
 	"runtime"
 	"runtime/pprof"
-	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"text/tabwriter"
 
 	log "github.com/sirupsen/logrus"
 
 	"bufio"
 
 	"github.com/miku/span"
-	"github.com/miku/span/formats/ceeol"
-	"github.com/miku/span/formats/crossref"
-	"github.com/miku/span/formats/degruyter"
-	"github.com/miku/span/formats/disson"
-	"github.com/miku/span/formats/doaj"
-	"github.com/miku/span/formats/dummy"
-	"github.com/miku/span/formats/elsevier"
+	"github.com/miku/span/assetutil"
+	"github.com/miku/span/formats"
+	_ "github.com/miku/span/formats/all"
 	"github.com/miku/span/formats/finc"
-	"github.com/miku/span/formats/genderopen"
-	"github.com/miku/span/formats/genios"
-	"github.com/miku/span/formats/hhbd"
-	"github.com/miku/span/formats/highwire"
-	"github.com/miku/span/formats/ieee"
-	"github.com/miku/span/formats/imslp"
-	"github.com/miku/span/formats/jstor"
-	"github.com/miku/span/formats/olms"
-	"github.com/miku/span/formats/ssoar"
-	"github.com/miku/span/formats/thieme"
-	"github.com/miku/span/formats/zvdd"
 	"github.com/miku/span/parallel"
-	"github.com/miku/xmlstream"
 )
 
 var (
 	name        = flag.String("i", "", "input format name")
+	config      = flag.String("c", "", "format specific configuration file, e.g. a MARC mapping")
 	list        = flag.Bool("list", false, "list input formats")
+	verbose     = flag.Bool("verbose", false, "list input formats with shape, source id and description, log the origin of each asset map")
+	assets      = flag.String("assets", "", "directory or single JSON file with asset maps, overriding the embedded ones")
 	numWorkers  = flag.Int("w", runtime.NumCPU(), "number of workers")
+	batchSize   = flag.Int("b", 2000, "number of XML elements per batch")
+	ordered     = flag.Bool("ordered", false, "keep input order for XML formats")
 	showVersion = flag.Bool("v", false, "prints current program version")
 	cpuProfile  = flag.String("cpuprofile", "", "write cpu profile to file")
+	policy      = flag.String("errors", "fail", "error policy: fail, skip or collect")
+	reportFile  = flag.String("report", "", "write a JSON report about skipped and failed records to file")
+	profileFile = flag.String("profile", "", "source profile to apply after conversion, sets source id, collections and defaults")
+
+	// report collects skip reasons and errors.
+	report *span.Report
+	// profile is applied to each converted record, if set.
+	profile *formats.Profile
 )
 
-// Factory creates things.
-type Factory func() interface{}
-
-// FormatMap maps format name to pointer to format struct.
-var FormatMap = map[string]Factory{
-	"ceeol":      func() interface{} { return new(ceeol.Article) },
-	"crossref":   func() interface{} { return new(crossref.Document) },
-	"degruyter":  func() interface{} { return new(degruyter.Article) },
-	"disson":     func() interface{} { return new(disson.Record) },
-	"doaj":       func() interface{} { return new(doaj.Response) },
-	"doaj-api":   func() interface{} { return new(doaj.ArticleV1) },
-	"dummy":      func() interface{} { return new(dummy.Example) },
-	"genderopen": func() interface{} { return new(genderopen.Record) },
-	"genios":     func() interface{} { return new(genios.Document) },
-	"hhbd":       func() interface{} { return new(hhbd.Record) },
-	"highwire":   func() interface{} { return new(highwire.Record) },
-	"ieee":       func() interface{} { return new(ieee.Publication) },
-	"imslp":      func() interface{} { return new(imslp.Data) },
-	"jstor":      func() interface{} { return new(jstor.Article) },
-	"olms":       func() interface{} { return new(olms.Record) },
-	"olms-mets":  func() interface{} { return new(olms.MetsRecord) },
-	"ssoar":      func() interface{} { return new(ssoar.Record) },
-	"thieme-nlm": func() interface{} { return new(thieme.Record) },
-	"zvdd-mets":  func() interface{} { return new(zvdd.MetsRecord) },
-	"zvdd":       func() interface{} { return new(zvdd.DublicCoreRecord) },
+// recordBatch is a numbered batch of decoded records, e.g. XML elements, with
+// the input offsets of the records.
+type recordBatch struct {
+	seq      int64
+	elements []interface{}
+	offsets  []int64
 }
 
-// IntermediateSchemaer wrap a basic conversion method.
-type IntermediateSchemaer interface {
-	ToIntermediateSchema() (*finc.IntermediateSchema, error)
+// batchResult contains the serialized conversion results of a batch.
+type batchResult struct {
+	seq int64
+	b   []byte
+	err error
 }
 
-// processXML converts XML based formats, given a format name. It reads XML as
-// stream and converts record them to an intermediate // schema (at the
-// moment).
-func processXML(r io.Reader, w io.Writer, name string) error {
-	if _, ok := FormatMap[name]; !ok {
-		return fmt.Errorf("unknown format name: %s", name)
-	}
-	obj := FormatMap[name]()
-	scanner := xmlstream.NewScanner(bufio.NewReader(r), obj)
-	scanner.Decoder.Strict = false // Errors of the invalid character entity kind are common.
-	for scanner.Scan() {
-		tag := scanner.Element()
-		converter, ok := tag.(IntermediateSchemaer)
-		if !ok {
-			return fmt.Errorf("cannot convert to intermediate schema: %T", tag)
+// recordID returns an identifier of a possibly incomplete record, if any.
+func recordID(is *finc.IntermediateSchema) string {
+	if is == nil {
+		return ""
+	}
+	if is.ID != "" {
+		return is.ID
+	}
+	return is.RecordID
+}
+
+// finalize applies the source profile, if any, and counts the record.
+func finalize(output *finc.IntermediateSchema) error {
+	if profile != nil {
+		if err := profile.Apply(output); err != nil {
+			return err
 		}
-		output, err := converter.ToIntermediateSchema()
-		if err != nil {
-			if _, ok := err.(span.Skip); ok {
+	}
+	report.Success()
+	return nil
+}
+
+// convertElement converts a single decoded element and encodes the result.
+// Skipped and failed records are added to the report and not encoded. An
+// error is returned only, if the conversion should stop.
+func convertElement(v interface{}, enc *json.Encoder, offset int64) error {
+	converter, ok := v.(formats.IntermediateSchemaer)
+	if !ok {
+		return fmt.Errorf("cannot convert to intermediate schema: %T", v)
+	}
+	output, err := converter.ToIntermediateSchema()
+	if err != nil {
+		return report.Add(err, recordID(output), offset)
+	}
+	if err := finalize(output); err != nil {
+		return err
+	}
+	return enc.Encode(output)
+}
+
+// processRecords converts records returned by next, until next returns
+// io.EOF. Decoding is sequential, conversion and encoding of batches of
+// records run in parallel. With -ordered, the output has the same order as
+// the input. At most -w batches are in flight, so a slow batch does not let
+// later, converted batches pile up in memory.
+func processRecords(next func() (interface{}, int64, error), w io.Writer) error {
+	var (
+		queue   = make(chan recordBatch)
+		results = make(chan batchResult)
+		done    = make(chan error)
+		wg      sync.WaitGroup
+		// failed is set by the writer on the first error, so no more batches
+		// get queued.
+		failed int32
+		// inflight is acquired for each queued batch and released, when the
+		// batch has been written or dropped.
+		inflight = make(chan struct{}, *numWorkers)
+	)
+	release := func(n int) {
+		for i := 0; i < n; i++ {
+			<-inflight
+		}
+	}
+
+	worker := func() {
+		defer wg.Done()
+		for batch := range queue {
+			var (
+				buf bytes.Buffer
+				enc = json.NewEncoder(&buf)
+				err error
+			)
+			for i, v := range batch.elements {
+				if err = convertElement(v, enc, batch.offsets[i]); err != nil {
+					break
+				}
+			}
+			results <- batchResult{seq: batch.seq, b: buf.Bytes(), err: err}
+		}
+	}
+
+	writer := func() {
+		var (
+			err     error
+			next    int64
+			pending = make(map[int64][]byte)
+		)
+		for result := range results {
+			if err != nil {
+				release(1) // Drain.
 				continue
 			}
-			return err
+			if result.err != nil {
+				err = result.err
+				atomic.StoreInt32(&failed, 1)
+				release(1 + len(pending))
+				continue
+			}
+			if !*ordered {
+				if _, err = w.Write(result.b); err != nil {
+					atomic.StoreInt32(&failed, 1)
+				}
+				release(1)
+				continue
+			}
+			pending[result.seq] = result.b
+			for {
+				b, ok := pending[next]
+				if !ok {
+					break
+				}
+				delete(pending, next)
+				next++
+				release(1)
+				if _, err = w.Write(b); err != nil {
+					atomic.StoreInt32(&failed, 1)
+					release(len(pending))
+					break
+				}
+			}
 		}
-		if err := json.NewEncoder(w).Encode(output); err != nil {
-			return err
+		done <- err
+	}
+
+	go writer()
+	for i := 0; i < *numWorkers; i++ {
+		wg.Add(1)
+		go worker()
+	}
+
+	var (
+		seq     int64
+		batch   recordBatch
+		readErr error
+	)
+	for {
+		v, offset, err := next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			readErr = err
+			break
 		}
+		batch.elements = append(batch.elements, v)
+		batch.offsets = append(batch.offsets, offset)
+		if len(batch.elements) < *batchSize {
+			continue
+		}
+		if atomic.LoadInt32(&failed) == 1 {
+			break
+		}
+		inflight <- struct{}{}
+		queue <- batch
+		seq++
+		batch = recordBatch{seq: seq}
+	}
+	if len(batch.elements) > 0 {
+		inflight <- struct{}{}
+		queue <- batch
 	}
-	return scanner.Err()
+	close(queue)
+	wg.Wait()
+	close(results)
+	if err := <-done; err != nil {
+		return err
+	}
+	return readErr
 }
 
-// processJSON convert JSON based formats. Input is interpreted as newline delimited JSON.
-func processJSON(r io.Reader, w io.Writer, name string) error {
-	if _, ok := FormatMap[name]; !ok {
-		return fmt.Errorf("unknown format name: %s", name)
+// elementName returns the local name of the XML element of a record, as
+// given by the XMLName field of the record struct.
+func elementName(v interface{}) string {
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	field, ok := t.FieldByName("XMLName")
+	if !ok {
+		return ""
+	}
+	name := strings.Split(field.Tag.Get("xml"), ",")[0]
+	if i := strings.LastIndex(name, " "); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
+
+// processXML converts XML based formats. It reads XML as stream and converts
+// record them to an intermediate schema (at the moment). Records are created
+// with the factory of the format, so they can carry configuration.
+func processXML(r io.Reader, w io.Writer, f formats.Format) error {
+	report.OffsetUnit = "byte"
+	name := elementName(f.New())
+	if name == "" {
+		return fmt.Errorf("%s: record has no XML element name", f.Name)
 	}
-	p := parallel.NewProcessor(r, w, func(_ int64, b []byte) ([]byte, error) {
-		v := FormatMap[name]()
+	dec := xml.NewDecoder(bufio.NewReader(r))
+	dec.Strict = false // Errors of the invalid character entity kind are common.
+	return processRecords(func() (interface{}, int64, error) {
+		for {
+			offset := dec.InputOffset()
+			token, err := dec.Token()
+			if err != nil {
+				return nil, offset, err
+			}
+			if se, ok := token.(xml.StartElement); ok && se.Name.Local == name {
+				v := f.New()
+				if err := dec.DecodeElement(v, &se); err != nil {
+					return nil, offset, err
+				}
+				return v, offset, nil
+			}
+		}
+	}, w)
+}
+
+// processStream converts formats with a custom record serialization, e.g.
+// MARC, read with the record reader of the format.
+func processStream(r io.Reader, w io.Writer, f formats.Format) error {
+	report.OffsetUnit = "byte"
+	return processRecords(f.Records(r).Read, w)
+}
+
+// processJSON convert JSON based formats. Input is interpreted as newline delimited JSON.
+func processJSON(r io.Reader, w io.Writer, f formats.Format) error {
+	report.OffsetUnit = "line"
+	p := parallel.NewProcessor(r, w, func(lineno int64, b []byte) ([]byte, error) {
+		v := f.New()
 		if err := json.Unmarshal(b, v); err != nil {
-			return nil, err
+			return nil, report.Add(err, "", lineno)
 		}
-		converter, ok := v.(IntermediateSchemaer)
+		converter, ok := v.(formats.IntermediateSchemaer)
 		if !ok {
 			return nil, fmt.Errorf("cannot convert to intermediate schema: %T", v)
 		}
 		output, err := converter.ToIntermediateSchema()
-		if _, ok := err.(span.Skip); ok {
-			return nil, nil
-		}
 		if err != nil {
+			return nil, report.Add(err, recordID(output), lineno)
+		}
+		if err := finalize(output); err != nil {
 			return nil, err
 		}
 		bb, err := json.Marshal(output)
@@ -143,12 +321,9 @@ func processJSON(r io.Reader, w io.Writer, name string) error {
 }
 
 // processText processes a single record from raw bytes.
-func processText(r io.Reader, w io.Writer, name string) error {
-	if _, ok := FormatMap[name]; !ok {
-		return fmt.Errorf("unknown format name: %s", name)
-	}
+func processText(r io.Reader, w io.Writer, f formats.Format) error {
 	// Get the format.
-	data := FormatMap[name]()
+	data := f.New()
 
 	// We need an unmarshaller first.
 	unmarshaler, ok := data.(encoding.TextUnmarshaler)
@@ -164,20 +339,51 @@ func processText(r io.Reader, w io.Writer, name string) error {
 	}
 
 	// Now that data is populated we can convert.
-	converter, ok := data.(IntermediateSchemaer)
+	converter, ok := data.(formats.IntermediateSchemaer)
 	if !ok {
 		return fmt.Errorf("cannot convert to intermediate schema: %T", data)
 	}
 	output, err := converter.ToIntermediateSchema()
-	if _, ok := err.(span.Skip); ok {
-		return nil
-	}
 	if err != nil {
+		return report.Add(err, recordID(output), -1)
+	}
+	if err := finalize(output); err != nil {
 		return err
 	}
 	return json.NewEncoder(w).Encode(output)
 }
 
+// archiveEmitter returns a function, that writes records emitted by archive
+// formats to w.
+func archiveEmitter(w io.Writer) formats.EmitFunc {
+	encoder := json.NewEncoder(w)
+	return func(output *finc.IntermediateSchema, err error) error {
+		if err != nil {
+			return report.Add(err, recordID(output), -1)
+		}
+		if err := finalize(output); err != nil {
+			return err
+		}
+		return encoder.Encode(output)
+	}
+}
+
+// processArchive hands the whole input to the conversion function of the format.
+func processArchive(r io.Reader, w io.Writer, f formats.Format) error {
+	return f.Convert(r, archiveEmitter(w))
+}
+
+// writeReport writes the conversion report to a file.
+func writeReport(filename string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = report.WriteTo(f)
+	return err
+}
+
 func main() {
 	flag.Parse()
 
@@ -196,70 +402,100 @@ func main() {
 	}
 
 	if *list {
-		var keys []string
-		for k := range FormatMap {
-			keys = append(keys, k)
+		if !*verbose {
+			for _, name := range formats.Names() {
+				fmt.Println(name)
+			}
+			os.Exit(0)
 		}
-		sort.Strings(keys)
-		for _, k := range keys {
-			fmt.Println(k)
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, f := range formats.All() {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", f.Name, f.Shape, f.SourceID, f.Description)
 		}
+		tw.Flush()
 		os.Exit(0)
 	}
 
+	if *assets != "" {
+		if err := assetutil.SetAssets(*assets); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if *verbose {
+		for _, info := range assetutil.Loaded() {
+			log.Printf("%s: %s (%0.8s)", info.Path, info.Origin, info.SHA1)
+		}
+	}
+
 	w := bufio.NewWriter(os.Stdout)
 	defer w.Flush()
 
-	var reader io.Reader = os.Stdin
-
-	if flag.NArg() > 0 {
-		var files []io.Reader
-		for _, filename := range flag.Args() {
-			f, err := os.Open(filename)
-			if err != nil {
-				log.Fatal(err)
-			}
-			defer f.Close()
-			files = append(files, f)
-		}
-		reader = io.MultiReader(files...)
+	if *name == "" {
+		log.Fatalf("input format required")
 	}
-
-	switch *name {
-	// XXX: Configure this in one place.
-	case "highwire", "ceeol", "ieee", "genios", "jstor", "thieme-tm",
-		"zvdd", "degruyter", "zvdd-mets", "hhbd", "thieme-nlm", "olms",
-		"olms-mets", "ssoar", "disson", "genderopen":
-		if err := processXML(reader, w, *name); err != nil {
-			log.Fatal(err)
-		}
-	case "doaj", "doaj-api", "crossref", "dummy":
-		if err := processJSON(reader, w, *name); err != nil {
-			log.Fatal(err)
+	f, ok := formats.Lookup(*name)
+	if !ok {
+		log.Fatalf("unknown format: %s", *name)
+	}
+	if *config != "" {
+		if f.Configure == nil {
+			log.Fatalf("format %s takes no configuration", *name)
 		}
-	case "imslp":
-		if err := processText(reader, w, *name); err != nil {
+		var err error
+		if f, err = f.Configure(*config); err != nil {
 			log.Fatal(err)
 		}
-	case "elsevier-tar":
-		shipment, err := elsevier.NewShipment(reader)
-		if err != nil {
-			log.Fatal(err)
-		}
-		docs, err := shipment.BatchConvert()
-		if err != nil {
+	}
+
+	errorPolicy, err := span.ParseErrorPolicy(*policy)
+	if err != nil {
+		log.Fatal(err)
+	}
+	report = span.NewReport(f.Name, errorPolicy)
+	if *profileFile != "" {
+		if profile, err = formats.LoadProfile(*profileFile); err != nil {
 			log.Fatal(err)
 		}
-		encoder := json.NewEncoder(w)
-		for _, doc := range docs {
-			if encoder.Encode(doc); err != nil {
-				log.Fatal(err)
+	}
+
+	// Compressed files are decompressed, archives are unpacked and their
+	// members processed one after another, except for archive formats, which
+	// handle archives themselves. With text formats, each file or archive
+	// member is a single record, stream formats read records from each file
+	// or member.
+	unpack := f.Shape != formats.Archive
+	if f.Shape == formats.Archive && f.ConvertFiles != nil && flag.NArg() > 0 {
+		err = f.ConvertFiles(flag.Args(), archiveEmitter(w))
+	} else {
+		err = span.WalkInput(flag.Args(), unpack, func(name string, r io.Reader) error {
+			report.SetInput(name)
+			switch f.Shape {
+			case formats.XML:
+				return processXML(r, w, f)
+			case formats.NDJSON:
+				return processJSON(r, w, f)
+			case formats.Text:
+				return processText(r, w, f)
+			case formats.Stream:
+				return processStream(r, w, f)
+			case formats.Archive:
+				return processArchive(r, w, f)
+			default:
+				return fmt.Errorf("unsupported shape: %s", f.Shape)
 			}
+		})
+	}
+	if *reportFile != "" {
+		if werr := writeReport(*reportFile); werr != nil {
+			log.Fatal(werr)
 		}
-	default:
-		if *name == "" {
-			log.Fatalf("input format required")
-		}
-		log.Fatalf("unknown format: %s", *name)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Debug(report)
+	if report.Failed() {
+		w.Flush()
+		log.Fatalf("conversion failed, %d errors", report.ErrorCount)
 	}
 }
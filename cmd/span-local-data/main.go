@@ -14,6 +14,7 @@ import (
 
 	log "github.com/sirupsen/logrus"
 
+	"github.com/miku/span"
 	"github.com/miku/span/parallel"
 )
 
@@ -37,7 +38,10 @@ func main() {
 	bw := bufio.NewWriter(os.Stdout)
 	defer bw.Flush()
 
-	p := parallel.NewProcessor(os.Stdin, os.Stdout, func(_ int64, b []byte) ([]byte, error) {
+	reader := span.OpenInput(flag.Args()...)
+	defer reader.Close()
+
+	p := parallel.NewProcessor(reader, os.Stdout, func(_ int64, b []byte) ([]byte, error) {
 		var doc record
 		if err := json.Unmarshal(b, &doc); err != nil {
 			return nil, err
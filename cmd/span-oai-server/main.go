@@ -0,0 +1,67 @@
+// span-oai-server serves intermediate schema files over OAI-PMH, with oai_dc
+// and the native intermediate schema (is) as metadata formats.
+//
+//     $ span-oai-server -addr :8090 -base-url http://example.com/oai \
+//         -labels DE-15,DE-14 ai-1.ldj ai-2.ldj
+//     $ curl "localhost:8090/oai?verb=ListRecords&metadataPrefix=oai_dc&set=label:DE-15"
+//
+// Files must be uncompressed, records are read from disk on request. Sets are
+// source ids (source:49), collections (collection:Crossref) and labels
+// (label:DE-15). The datestamp of a record is the modification time of its
+// file.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/miku/span"
+	"github.com/miku/span/oai"
+	log "github.com/sirupsen/logrus"
+)
+
+func main() {
+	addr := flag.String("addr", ":8090", "hostport to listen on")
+	baseURL := flag.String("base-url", "", "public base URL of the endpoint, defaults to http://localhost<addr>/oai")
+	name := flag.String("name", "span", "repository name")
+	email := flag.String("email", "admin@localhost", "admin email")
+	identifier := flag.String("identifier", "span", "repository identifier, used in OAI identifiers like oai:span:ai-49-...")
+	labels := flag.String("labels", "", "serve only records with one of these comma separated labels, e.g. ISIL")
+	size := flag.Int("b", 100, "records per response")
+	showVersion := flag.Bool("v", false, "prints current program version")
+
+	flag.Parse()
+
+	if *showVersion {
+		fmt.Println(span.AppVersion)
+		os.Exit(0)
+	}
+	if flag.NArg() == 0 {
+		log.Fatal("intermediate schema file required")
+	}
+	if *baseURL == "" {
+		*baseURL = fmt.Sprintf("http://localhost%s/oai", *addr)
+	}
+
+	p := oai.NewProvider(*baseURL)
+	p.RepositoryName = *name
+	p.AdminEmail = *email
+	p.Identifier = *identifier
+	p.PageSize = *size
+	if *labels != "" {
+		p.Labels = strings.Split(*labels, ",")
+	}
+	for _, filename := range flag.Args() {
+		if err := p.AddFile(filename); err != nil {
+			log.Fatal(err)
+		}
+	}
+	defer p.Close()
+
+	http.Handle("/oai", p)
+	log.Printf("listening on %s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, nil))
+}
@@ -31,25 +31,10 @@ func main() {
 		os.Exit(0)
 	}
 
-	var reader io.Reader = os.Stdin
-
-	if flag.NArg() > 0 {
-		var files []io.Reader
-		for _, filename := range flag.Args() {
-			f, err := os.Open(filename)
-			if err != nil {
-				log.Fatal(err)
-			}
-			defer f.Close()
-			files = append(files, f)
-		}
-		reader = io.MultiReader(files...)
-	}
-
 	w := bufio.NewWriter(os.Stdout)
 	defer w.Flush()
 
-	p := parallel.NewProcessor(bufio.NewReader(reader), w, func(_ int64, b []byte) ([]byte, error) {
+	transform := func(_ int64, b []byte) ([]byte, error) {
 		is := finc.IntermediateSchema{}
 
 		if err := json.Unmarshal(b, &is); err != nil {
@@ -66,12 +51,16 @@ func main() {
 		}
 		bb = append(bb, '\n')
 		return bb, nil
-	})
-
-	p.NumWorkers = *numWorkers
-	p.BatchSize = *size
+	}
 
-	if err := p.Run(); err != nil {
+	// Process members one by one, so errors carry the file or member name.
+	err := span.WalkInput(flag.Args(), true, func(_ string, r io.Reader) error {
+		p := parallel.NewProcessor(bufio.NewReader(r), w, transform)
+		p.NumWorkers = *numWorkers
+		p.BatchSize = *size
+		return p.Run()
+	})
+	if err != nil {
 		log.Fatal(err)
 	}
 }
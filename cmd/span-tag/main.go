@@ -4,6 +4,11 @@
 //
 // $ span-tag -c '{"DE-15": {"any": {}}}' < input.ldj > output.ldj
 //
+// With -explain, the evaluated filter tree is written for each record and
+// label instead, optionally limited to some record ids:
+//
+// $ span-tag -c filterconfig.json -explain -ids ai-49-aHR0... < input.ldj
+//
 package main
 
 import (
@@ -15,6 +20,7 @@ import (
 	"os"
 	"runtime"
 	"runtime/pprof"
+	"strings"
 
 	log "github.com/sirupsen/logrus"
 
@@ -31,6 +37,8 @@ func main() {
 	numWorkers := flag.Int("w", runtime.NumCPU(), "number of workers")
 	cpuProfile := flag.String("cpuprofile", "", "write cpu profile to file")
 	unfreeze := flag.String("unfreeze", "", "unfreeze filterconfig from a frozen file")
+	explain := flag.Bool("explain", false, "write the evaluated filter tree per record and label instead of tagged records")
+	ids := flag.String("ids", "", "limit -explain to these comma separated record ids or ids from a file, one per line")
 
 	flag.Parse()
 
@@ -79,30 +87,42 @@ func main() {
 		}
 	}
 
-	w := bufio.NewWriter(os.Stdout)
-	defer w.Flush()
-
-	var reader io.Reader = os.Stdin
-
-	if flag.NArg() > 0 {
-		var files []io.Reader
-		for _, filename := range flag.Args() {
-			f, err := os.Open(filename)
-			if err != nil {
+	// Record ids to explain, all if empty.
+	explainIDs := make(map[string]bool)
+	if *ids != "" {
+		values := strings.Split(*ids, ",")
+		if _, err := os.Stat(*ids); err == nil {
+			if values, err = span.ReadLines(*ids); err != nil {
 				log.Fatal(err)
 			}
-			defer f.Close()
-			files = append(files, f)
 		}
-		reader = io.MultiReader(files...)
+		for _, v := range values {
+			if v = strings.TrimSpace(v); v != "" {
+				explainIDs[v] = true
+			}
+		}
 	}
 
-	p := parallel.NewProcessor(bufio.NewReader(reader), w, func(_ int64, b []byte) ([]byte, error) {
+	w := bufio.NewWriter(os.Stdout)
+	defer w.Flush()
+
+	transform := func(_ int64, b []byte) ([]byte, error) {
 		var is finc.IntermediateSchema
 		if err := json.Unmarshal(b, &is); err != nil {
 			return b, err
 		}
 
+		if *explain {
+			if len(explainIDs) > 0 && !explainIDs[is.ID] {
+				return nil, nil
+			}
+			bb, err := json.Marshal(tagger.Explain(is))
+			if err != nil {
+				return bb, err
+			}
+			return append(bb, '\n'), nil
+		}
+
 		tagged := tagger.Tag(is)
 
 		bb, err := json.Marshal(tagged)
@@ -111,12 +131,16 @@ func main() {
 		}
 		bb = append(bb, '\n')
 		return bb, nil
-	})
-
-	p.NumWorkers = *numWorkers
-	p.BatchSize = *size
+	}
 
-	if err := p.Run(); err != nil {
+	// Process members one by one, so errors carry the file or member name.
+	err = span.WalkInput(flag.Args(), true, func(_ string, r io.Reader) error {
+		p := parallel.NewProcessor(bufio.NewReader(r), w, transform)
+		p.NumWorkers = *numWorkers
+		p.BatchSize = *size
+		return p.Run()
+	})
+	if err != nil {
 		log.Fatal(err)
 	}
 }
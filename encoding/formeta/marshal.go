@@ -59,6 +59,13 @@ func marshal(w io.Writer, k string, v interface{}) error {
 		if _, err := io.WriteString(w, " } "); err != nil {
 			return err
 		}
+	case reflect.Ptr:
+		// Nil pointers are omitted, like empty strings.
+		vv := reflect.ValueOf(v)
+		if vv.IsNil() {
+			return nil
+		}
+		return marshal(w, k, vv.Elem().Interface())
 	case reflect.Slice:
 		vv := reflect.ValueOf(v)
 		for i := 0; i < vv.Len(); i++ {
@@ -19,6 +19,8 @@ func TestEncoding(t *testing.T) {
 		{in: struct{ A []string }{A: []string{"B", "C"}}, out: `{ A: 'B', A: 'C',  }`, err: nil},
 		{in: struct{ A int }{A: 1}, out: `{ A: 1,  }`, err: nil},
 		{in: struct{ A int64 }{A: 1}, out: `{ A: 1,  }`, err: nil},
+		{in: struct{ A *TestPosition }{}, out: `{  }`, err: nil},
+		{in: struct{ A *TestPosition }{A: &TestPosition{Longitude: 1}}, out: `{ A { Longitude: 1.000000, Latitude: 0.000000,  }  }`, err: nil},
 		{
 			in: struct{ A string }{A: `B
 A`}, out: `{ A: 'B\nA',  }`, err: nil,
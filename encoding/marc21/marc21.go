@@ -0,0 +1,191 @@
+// Package marc21 writes MARC 21 bibliographic records as MARC-XML or in the
+// binary transmission format (ISO 2709).
+package marc21
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	fieldTerminator   = 0x1e
+	recordTerminator  = 0x1d
+	subfieldDelimiter = 0x1f
+	leaderLength      = 24
+	directoryEntry    = 12
+
+	// MaxRecordLength is the limit of the binary format, in bytes.
+	MaxRecordLength = 99999
+
+	// Namespace of MARC-XML records.
+	Namespace = "http://www.loc.gov/MARC21/slim"
+)
+
+var (
+	// ErrFieldTooLong signals a field, that exceeds the binary limit of 9999 bytes.
+	ErrFieldTooLong = errors.New("marc21: field too long")
+	// ErrRecordTooLong signals a record, that exceeds the binary limit of 99999 bytes.
+	ErrRecordTooLong = errors.New("marc21: record too long")
+	// ErrInvalidLeader signals a leader, that is not 24 bytes long.
+	ErrInvalidLeader = errors.New("marc21: leader must be 24 bytes")
+)
+
+// Subfield of a data field.
+type Subfield struct {
+	Code  string `xml:"code,attr"`
+	Value string `xml:",chardata"`
+}
+
+// Controlfield is a field without indicators and subfields, e.g. 001 or 008.
+type Controlfield struct {
+	Tag   string `xml:"tag,attr"`
+	Value string `xml:",chardata"`
+}
+
+// Datafield has two indicators and a list of subfields.
+type Datafield struct {
+	Tag       string     `xml:"tag,attr"`
+	Ind1      string     `xml:"ind1,attr"`
+	Ind2      string     `xml:"ind2,attr"`
+	Subfields []Subfield `xml:"subfield"`
+}
+
+// Record is a MARC record. Record length and base address in the leader are
+// computed, when the record is marshaled in binary form.
+type Record struct {
+	XMLName       xml.Name       `xml:"record"`
+	Xmlns         string         `xml:"xmlns,attr,omitempty"`
+	Leader        string         `xml:"leader"`
+	Controlfields []Controlfield `xml:"controlfield"`
+	Datafields    []Datafield    `xml:"datafield"`
+}
+
+// Sub is a shortcut for a subfield.
+func Sub(code, value string) Subfield {
+	return Subfield{Code: code, Value: value}
+}
+
+// AddControlfield adds a control field, if the value is not empty.
+func (r *Record) AddControlfield(tag, value string) {
+	if value == "" {
+		return
+	}
+	r.Controlfields = append(r.Controlfields, Controlfield{Tag: tag, Value: value})
+}
+
+// AddDatafield adds a data field with all non-empty subfields. Fields without
+// any non-empty subfield are not added. Empty indicators are blanks.
+func (r *Record) AddDatafield(tag, ind1, ind2 string, subfields ...Subfield) {
+	var nonEmpty []Subfield
+	for _, sf := range subfields {
+		sf.Value = strings.TrimSpace(sf.Value)
+		if sf.Value != "" {
+			nonEmpty = append(nonEmpty, sf)
+		}
+	}
+	if len(nonEmpty) == 0 {
+		return
+	}
+	if ind1 == "" {
+		ind1 = " "
+	}
+	if ind2 == "" {
+		ind2 = " "
+	}
+	r.Datafields = append(r.Datafields, Datafield{Tag: tag, Ind1: ind1, Ind2: ind2, Subfields: nonEmpty})
+}
+
+// indicator returns the first byte of an indicator or a blank.
+func indicator(s string) byte {
+	if s == "" {
+		return ' '
+	}
+	return s[0]
+}
+
+// Len returns the number of bytes of the field in binary form, with
+// indicators and field terminator, but without directory entry.
+func (f Datafield) Len() int {
+	n := 3
+	for _, sf := range f.Subfields {
+		n += 1 + len(sf.Code) + len(sf.Value)
+	}
+	return n
+}
+
+// Len returns the length of the record in binary form.
+func (r Record) Len() int {
+	n := leaderLength + 1 + 1 // Leader, directory and record terminator.
+	for _, f := range r.Controlfields {
+		n += directoryEntry + len(f.Value) + 1
+	}
+	for _, f := range r.Datafields {
+		n += directoryEntry + f.Len()
+	}
+	return n
+}
+
+// MarshalXML encodes a record as a single MARC-XML record element, with
+// namespace.
+func MarshalXML(r Record) ([]byte, error) {
+	r.Xmlns = Namespace
+	return xml.Marshal(r)
+}
+
+// Marshal encodes a record in the binary MARC 21 format. Data is expected to
+// be UTF-8, so leader position 9 is set to "a".
+func Marshal(r Record) ([]byte, error) {
+	if len(r.Leader) != leaderLength {
+		return nil, ErrInvalidLeader
+	}
+	var directory, data bytes.Buffer
+	addField := func(tag string, value []byte) error {
+		if len(value) > 9999 {
+			return fmt.Errorf("%v: %s", ErrFieldTooLong, tag)
+		}
+		fmt.Fprintf(&directory, "%03s%04d%05d", tag, len(value), data.Len())
+		data.Write(value)
+		return nil
+	}
+	for _, f := range r.Controlfields {
+		if err := addField(f.Tag, append([]byte(f.Value), fieldTerminator)); err != nil {
+			return nil, err
+		}
+	}
+	for _, f := range r.Datafields {
+		var buf bytes.Buffer
+		buf.WriteByte(indicator(f.Ind1))
+		buf.WriteByte(indicator(f.Ind2))
+		for _, sf := range f.Subfields {
+			buf.WriteByte(subfieldDelimiter)
+			buf.WriteString(sf.Code)
+			buf.WriteString(sf.Value)
+		}
+		buf.WriteByte(fieldTerminator)
+		if err := addField(f.Tag, buf.Bytes()); err != nil {
+			return nil, err
+		}
+	}
+	directory.WriteByte(fieldTerminator)
+	base := leaderLength + directory.Len()
+	length := base + data.Len() + 1
+	if length > MaxRecordLength {
+		return nil, ErrRecordTooLong
+	}
+	leader := []byte(r.Leader)
+	copy(leader[0:5], fmt.Sprintf("%05d", length))
+	leader[9] = 'a'
+	copy(leader[10:12], "22")
+	copy(leader[12:17], fmt.Sprintf("%05d", base))
+	copy(leader[20:24], "4500")
+
+	var buf bytes.Buffer
+	buf.Write(leader)
+	buf.Write(directory.Bytes())
+	buf.Write(data.Bytes())
+	buf.WriteByte(recordTerminator)
+	return buf.Bytes(), nil
+}
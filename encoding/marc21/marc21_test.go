@@ -0,0 +1,64 @@
+package marc21
+
+import (
+	"strings"
+	"testing"
+)
+
+func testRecord() Record {
+	r := Record{Leader: "00000naa a2200000 u 4500"}
+	r.AddControlfield("001", "ai-1")
+	r.AddControlfield("003", "")
+	r.AddDatafield("245", "0", "0", Sub("a", "Über & <mehr>"), Sub("b", ""))
+	r.AddDatafield("500", "", "", Sub("a", ""))
+	return r
+}
+
+func TestAddDatafield(t *testing.T) {
+	r := testRecord()
+	if len(r.Controlfields) != 1 || len(r.Datafields) != 1 {
+		t.Fatalf("got %d control and %d data fields, want 1 and 1", len(r.Controlfields), len(r.Datafields))
+	}
+	if len(r.Datafields[0].Subfields) != 1 {
+		t.Errorf("got %v, want empty subfields dropped", r.Datafields[0].Subfields)
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	b, err := Marshal(testRecord())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "00074naa a2200049 u 4500" +
+		"001000500000" + "245001900005" + "\x1e" +
+		"ai-1\x1e" +
+		"00\x1faÜber & <mehr>\x1e" +
+		"\x1d"
+	if string(b) != want {
+		t.Errorf("got %q, want %q", b, want)
+	}
+	if n := testRecord().Len(); n != len(b) {
+		t.Errorf("Len: got %d, want %d", n, len(b))
+	}
+	if _, err := Marshal(Record{Leader: "short"}); err != ErrInvalidLeader {
+		t.Errorf("got %v, want %v", err, ErrInvalidLeader)
+	}
+	r := testRecord()
+	r.AddDatafield("520", "", "", Sub("a", strings.Repeat("x", 10000)))
+	if _, err := Marshal(r); err == nil {
+		t.Error("expected error for long field")
+	}
+}
+
+func TestMarshalXML(t *testing.T) {
+	b, err := MarshalXML(testRecord())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `<record xmlns="http://www.loc.gov/MARC21/slim"><leader>00000naa a2200000 u 4500</leader>` +
+		`<controlfield tag="001">ai-1</controlfield>` +
+		`<datafield tag="245" ind1="0" ind2="0"><subfield code="a">Über &amp; &lt;mehr&gt;</subfield></datafield></record>`
+	if string(b) != want {
+		t.Errorf("got %s, want %s", b, want)
+	}
+}
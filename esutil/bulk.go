@@ -0,0 +1,199 @@
+package esutil
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/miku/span"
+	log "github.com/sirupsen/logrus"
+)
+
+// ErrMissingDocument signals an action without a following document line.
+var ErrMissingDocument = errors.New("esutil: action without document")
+
+// bulkResponse contains the parts of a bulk response the loader cares about.
+type bulkResponse struct {
+	Errors bool `json:"errors"`
+	Items  []map[string]struct {
+		ID     string          `json:"_id"`
+		Status int             `json:"status"`
+		Error  json.RawMessage `json:"error"`
+	} `json:"items"`
+}
+
+// action is a single bulk action, with its document, if any.
+type action struct {
+	lines [][]byte
+}
+
+// Loader sends bulk requests to an Elasticsearch or OpenSearch server.
+// Requests are retried according to the embedded Retrier.
+type Loader struct {
+	span.Retrier
+	Server string // base URL, e.g. http://localhost:9200
+	Index  string // optional default index, if actions do not name one
+	// BatchSize is the number of actions per request.
+	BatchSize int
+
+	// Indexed and Failed count documents.
+	Indexed int
+	Failed  int
+}
+
+// NewLoader creates a loader with default settings.
+func NewLoader(server string) *Loader {
+	return &Loader{
+		Retrier: span.Retrier{
+			MaxRetries: 5,
+			Backoff:    2 * time.Second,
+			Client:     &http.Client{Timeout: 5 * time.Minute},
+		},
+		Server:    strings.TrimRight(server, "/"),
+		BatchSize: 1000,
+	}
+}
+
+// do sends a request with retries.
+func (l *Loader) do(method, link, contentType string, body []byte) ([]byte, error) {
+	return l.Do(func() (*http.Request, error) {
+		req, err := http.NewRequest(method, link, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", contentType)
+		return req, nil
+	})
+}
+
+// PutTemplate installs an index template.
+func (l *Loader) PutTemplate(name string, t *Template) error {
+	b, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	_, err = l.do("PUT", fmt.Sprintf("%s/_index_template/%s", l.Server, name), "application/json", b)
+	return err
+}
+
+// bulkURL returns the bulk endpoint, for the default index, if set.
+func (l *Loader) bulkURL() string {
+	if l.Index != "" {
+		return fmt.Sprintf("%s/%s/_bulk", l.Server, l.Index)
+	}
+	return fmt.Sprintf("%s/_bulk", l.Server)
+}
+
+// send indexes a batch of actions. Items rejected with 429, because the
+// server is busy, are sent again, other rejected items are counted as
+// failures and logged.
+func (l *Loader) send(batch []action) error {
+	delay := l.Backoff
+	for attempt := 0; len(batch) > 0; attempt++ {
+		if attempt > 0 {
+			if attempt > l.MaxRetries {
+				l.Failed += len(batch)
+				return fmt.Errorf("esutil: %d items rejected after %d retries", len(batch), l.MaxRetries)
+			}
+			log.Printf("[esutil] resending %d rejected items in %s", len(batch), delay)
+			time.Sleep(delay)
+			delay *= 2
+		}
+		var buf bytes.Buffer
+		for _, a := range batch {
+			for _, line := range a.lines {
+				buf.Write(line)
+				buf.WriteByte('\n')
+			}
+		}
+		b, err := l.do("POST", l.bulkURL(), "application/x-ndjson", buf.Bytes())
+		if err != nil {
+			return err
+		}
+		var resp bulkResponse
+		if err := json.Unmarshal(b, &resp); err != nil {
+			return fmt.Errorf("cannot parse bulk response: %v", err)
+		}
+		if !resp.Errors {
+			l.Indexed += len(batch)
+			return nil
+		}
+		if len(resp.Items) != len(batch) {
+			return fmt.Errorf("esutil: got %d items for %d actions", len(resp.Items), len(batch))
+		}
+		var rejected []action
+		for i, item := range resp.Items {
+			for _, result := range item {
+				switch {
+				case result.Status == http.StatusTooManyRequests:
+					rejected = append(rejected, batch[i])
+				case result.Status >= 300:
+					log.Printf("[esutil] %s failed with %d: %s", result.ID, result.Status, result.Error)
+					l.Failed++
+				default:
+					l.Indexed++
+				}
+			}
+		}
+		batch = rejected
+	}
+	return nil
+}
+
+// Load reads bulk actions and documents from r and sends them in batches.
+// Delete actions have no document line. Failed documents are logged and
+// counted, the load continues.
+func (l *Loader) Load(r io.Reader) error {
+	size := l.BatchSize
+	if size < 1 {
+		size = 1000
+	}
+	br := bufio.NewReader(r)
+	var batch []action
+	var pending *action
+	for {
+		line, err := br.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return err
+		}
+		line = bytes.TrimSpace(line)
+		if len(line) > 0 {
+			if pending != nil {
+				pending.lines = append(pending.lines, line)
+				batch = append(batch, *pending)
+				pending = nil
+			} else {
+				var a map[string]json.RawMessage
+				if err := json.Unmarshal(line, &a); err != nil {
+					return fmt.Errorf("invalid action: %v", err)
+				}
+				next := action{lines: [][]byte{line}}
+				if _, ok := a["delete"]; ok {
+					batch = append(batch, next)
+				} else {
+					pending = &next
+				}
+			}
+		}
+		if len(batch) >= size || (err == io.EOF && len(batch) > 0) {
+			if err := l.send(batch); err != nil {
+				return err
+			}
+			log.Printf("[esutil] %d indexed, %d failed", l.Indexed, l.Failed)
+			batch = nil
+		}
+		if err == io.EOF {
+			break
+		}
+	}
+	if pending != nil {
+		return ErrMissingDocument
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package esutil
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/miku/span/formats/finc"
+)
+
+// stub returns a test server, that accepts bulk requests and records the
+// number of actions per request. The first request fails with 503, the
+// document with id "busy" is rejected once with 429 and the document with id
+// "bad" is always rejected with 400.
+func stub(t *testing.T, sizes *[]int) *httptest.Server {
+	var failed, busy bool
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ai/_bulk" || r.Method != "POST" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		if r.Header.Get("Content-Type") != "application/x-ndjson" {
+			t.Errorf("unexpected content type: %s", r.Header.Get("Content-Type"))
+		}
+		if !failed {
+			failed = true
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var items []string
+		var hasErrors bool
+		scanner := bufio.NewScanner(r.Body)
+		for scanner.Scan() {
+			var a map[string]struct {
+				ID string `json:"_id"`
+			}
+			if err := json.Unmarshal(scanner.Bytes(), &a); err != nil {
+				t.Error(err)
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			status := 201
+			for verb, meta := range a {
+				switch {
+				case meta.ID == "busy" && !busy:
+					busy, status, hasErrors = true, 429, true
+				case meta.ID == "bad":
+					status, hasErrors = 400, true
+				}
+				items = append(items, fmt.Sprintf(`{%q: {"_id": %q, "status": %d}}`, verb, meta.ID, status))
+				if verb != "delete" {
+					scanner.Scan()
+				}
+			}
+		}
+		*sizes = append(*sizes, len(items))
+		fmt.Fprintf(w, `{"errors": %v, "items": [%s]}`, hasErrors, strings.Join(items, ","))
+	}))
+}
+
+func TestLoad(t *testing.T) {
+	var sizes []int
+	ts := stub(t, &sizes)
+	defer ts.Close()
+
+	var buf bytes.Buffer
+	for _, id := range []string{"a", "busy", "b", "bad", "c"} {
+		fmt.Fprintf(&buf, `{"index": {"_id": %q}}`+"\n"+`{"title": %q}`+"\n", id, id)
+	}
+	buf.WriteString(`{"delete": {"_id": "d"}}` + "\n")
+
+	l := NewLoader(ts.URL)
+	l.Index = "ai"
+	l.BatchSize = 4
+	l.Backoff = 0
+	if err := l.Load(&buf); err != nil {
+		t.Fatal(err)
+	}
+	// Four actions, the busy document again, the rest.
+	if fmt.Sprintf("%v", sizes) != "[4 1 2]" {
+		t.Errorf("got batch sizes %v, want [4 1 2]", sizes)
+	}
+	if l.Indexed != 5 || l.Failed != 1 {
+		t.Errorf("got %d indexed, %d failed, want 5, 1", l.Indexed, l.Failed)
+	}
+}
+
+func TestLoadMissingDocument(t *testing.T) {
+	l := NewLoader("http://localhost:0")
+	if err := l.Load(strings.NewReader(`{"index": {"_id": "a"}}`)); err != ErrMissingDocument {
+		t.Errorf("got %v, want %v", err, ErrMissingDocument)
+	}
+}
+
+func TestNewTemplate(t *testing.T) {
+	m := &finc.Mapping{Fields: []finc.MappingField{
+		{Name: "id", Source: "finc.id", Type: "string"},
+		{Name: "title", Source: "Title()", Type: "string"},
+		{Name: "publishDateSort", Source: "Year()", Type: "int"},
+		{Name: "retracted", Source: "Retracted()", Type: "bool"},
+		{Name: "fullrecord", Source: "Fullrecord()", Type: "string"},
+	}}
+	tmpl := NewTemplate(m, "ai-*")
+	var cases = []struct {
+		name string
+		typ  string
+	}{
+		{"id", "keyword"},
+		{"title", "text"},
+		{"publishDateSort", "long"},
+		{"retracted", "boolean"},
+		{"fullrecord", "keyword"},
+	}
+	for _, c := range cases {
+		p, ok := tmpl.Template.Mappings.Properties[c.name].(map[string]interface{})
+		if !ok {
+			t.Errorf("%s: missing", c.name)
+			continue
+		}
+		if p["type"] != c.typ {
+			t.Errorf("%s: got %v, want %v", c.name, p["type"], c.typ)
+		}
+	}
+	if p := tmpl.Template.Mappings.Properties["fullrecord"].(map[string]interface{}); p["index"] != false {
+		t.Errorf("fullrecord should not be indexed")
+	}
+}
@@ -0,0 +1,85 @@
+// Package esutil implements helpers for Elasticsearch and OpenSearch indices:
+// an index template derived from an export mapping and a bulk loader.
+package esutil
+
+import (
+	"github.com/miku/span/formats/finc"
+)
+
+// TextFields are analyzed as full text, with a keyword subfield for sorting
+// and aggregations. All other string fields are keywords.
+var TextFields = map[string]bool{
+	"allfields":              true,
+	"author":                 true,
+	"author2":                true,
+	"container_title":        true,
+	"description":            true,
+	"event_name":             true,
+	"fulltext":               true,
+	"hierarchy_parent_title": true,
+	"imprint":                true,
+	"publisher":              true,
+	"series":                 true,
+	"title":                  true,
+	"title_full":             true,
+	"title_short":            true,
+	"title_sub":              true,
+	"topic":                  true,
+}
+
+// StoredFields are kept in the source, but not indexed, e.g. the original
+// record, which may exceed the keyword size limit.
+var StoredFields = map[string]bool{
+	"fullrecord": true,
+	"openurl":    true,
+}
+
+// Template is an index template, which works with the _index_template API
+// of Elasticsearch 7.8+ and OpenSearch.
+type Template struct {
+	IndexPatterns []string `json:"index_patterns"`
+	Template      struct {
+		Settings map[string]interface{} `json:"settings,omitempty"`
+		Mappings struct {
+			Dynamic    bool                   `json:"dynamic"`
+			Properties map[string]interface{} `json:"properties"`
+		} `json:"mappings"`
+	} `json:"template"`
+}
+
+// fieldProperty returns the index mapping for a single export field.
+func fieldProperty(f finc.MappingField) map[string]interface{} {
+	switch {
+	case StoredFields[f.Name]:
+		return map[string]interface{}{"type": "keyword", "index": false, "doc_values": false}
+	case f.Type == "int":
+		return map[string]interface{}{"type": "long"}
+	case f.Type == "bool":
+		return map[string]interface{}{"type": "boolean"}
+	case TextFields[f.Name]:
+		return map[string]interface{}{
+			"type": "text",
+			"fields": map[string]interface{}{
+				"keyword": map[string]interface{}{"type": "keyword", "ignore_above": 256},
+			},
+		}
+	}
+	return map[string]interface{}{"type": "keyword", "ignore_above": 1024}
+}
+
+// NewTemplate derives an index template from the fields of an export mapping.
+// Fields, that are not part of the mapping, are not indexed.
+func NewTemplate(m *finc.Mapping, patterns ...string) *Template {
+	t := &Template{IndexPatterns: patterns}
+	t.Template.Settings = map[string]interface{}{
+		"index.mapping.total_fields.limit": 2000,
+	}
+	t.Template.Mappings.Properties = make(map[string]interface{})
+	for _, f := range m.Fields {
+		if _, ok := t.Template.Mappings.Properties[f.Name]; ok {
+			continue
+		}
+		t.Template.Mappings.Properties[f.Name] = fieldProperty(f)
+	}
+	return t
+}
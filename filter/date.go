@@ -0,0 +1,139 @@
+package filter
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/miku/span/formats/finc"
+	"github.com/miku/span/licensing"
+)
+
+// now is the reference time for relative bounds.
+var now = time.Now
+
+// dateBound is a single bound of a date range.
+type dateBound struct {
+	t time.Time
+	g licensing.DateGranularity
+}
+
+// parseDateBound parses a date, like 2010 or 2010-04-01, or a duration
+// relative to the current date, like P2Y, P6M or P30D (the same syntax as
+// KBART embargoes). Relative bounds have day granularity.
+func parseDateBound(s string) (*dateBound, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	if strings.HasPrefix(s, "P") {
+		dur, err := licensing.Embargo(s).Duration()
+		if err != nil {
+			return nil, fmt.Errorf("invalid relative date: %s", s)
+		}
+		return &dateBound{t: now().Add(-dur), g: licensing.GRANULARITY_DAY}, nil
+	}
+	t, g, err := licensing.ParseWithGranularity(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date: %s", s)
+	}
+	return &dateBound{t: t, g: g}, nil
+}
+
+// compare compares a date to the bound, with the coarser granularity of
+// both, so 2010-04-01 and 2010 are equal. It returns -1, 0 or 1.
+func (b *dateBound) compare(t time.Time, g licensing.DateGranularity) int {
+	if b.g < g {
+		g = b.g
+	}
+	u, v := licensing.TruncateGranularity(t, g), licensing.TruncateGranularity(b.t, g)
+	if g == licensing.GRANULARITY_DAY {
+		u, v = u.Truncate(24*time.Hour), v.Truncate(24*time.Hour)
+	}
+	switch {
+	case u.Before(v):
+		return -1
+	case u.After(v):
+		return 1
+	}
+	return 0
+}
+
+// DateFilter allows records published within a date range. Bounds are
+// inclusive (from, until) or exclusive (after, before) and can be dates with
+// year, month or day granularity or relative to the current date:
+//
+//     {"date": {"from": "2010", "until": "2020"}}
+//     {"date": {"before": "P2Y"}}
+//
+// Records without a date do not match.
+type DateFilter struct {
+	From   *dateBound
+	Until  *dateBound
+	After  *dateBound
+	Before *dateBound
+}
+
+// recordDate returns the date of a record, as parsed from the raw date.
+func recordDate(is finc.IntermediateSchema) (time.Time, licensing.DateGranularity, bool) {
+	if t, g, err := licensing.ParseWithGranularity(is.RawDate); err == nil {
+		return t, g, true
+	}
+	if !is.Date.IsZero() {
+		return is.Date, licensing.GRANULARITY_DAY, true
+	}
+	return time.Time{}, licensing.GRANULARITY_DAY, false
+}
+
+// Apply filter.
+func (f *DateFilter) Apply(is finc.IntermediateSchema) bool {
+	t, g, ok := recordDate(is)
+	if !ok {
+		return false
+	}
+	if f.From != nil && f.From.compare(t, g) < 0 {
+		return false
+	}
+	if f.Until != nil && f.Until.compare(t, g) > 0 {
+		return false
+	}
+	if f.After != nil && f.After.compare(t, g) <= 0 {
+		return false
+	}
+	if f.Before != nil && f.Before.compare(t, g) >= 0 {
+		return false
+	}
+	return true
+}
+
+// UnmarshalJSON turns a config fragment into a filter.
+func (f *DateFilter) UnmarshalJSON(p []byte) (err error) {
+	var s struct {
+		Date struct {
+			From   string `json:"from"`
+			Until  string `json:"until"`
+			After  string `json:"after"`
+			Before string `json:"before"`
+		} `json:"date"`
+	}
+	if err := json.Unmarshal(p, &s); err != nil {
+		return err
+	}
+	if f.From, err = parseDateBound(s.Date.From); err != nil {
+		return err
+	}
+	if f.Until, err = parseDateBound(s.Date.Until); err != nil {
+		return err
+	}
+	if f.After, err = parseDateBound(s.Date.After); err != nil {
+		return err
+	}
+	if f.Before, err = parseDateBound(s.Date.Before); err != nil {
+		return err
+	}
+	if f.From == nil && f.Until == nil && f.After == nil && f.Before == nil {
+		return fmt.Errorf("date filter requires at least one bound")
+	}
+	return nil
+}
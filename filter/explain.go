@@ -0,0 +1,117 @@
+package filter
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/miku/span/formats/finc"
+	"github.com/miku/span/licensing"
+)
+
+// Explanation is the evaluated filter tree for a record: the result of each
+// filter and, for holdings filters, the checked entries.
+type Explanation struct {
+	Filter   string          `json:"filter"`
+	Result   bool            `json:"result"`
+	Children []*Explanation  `json:"children,omitempty"`
+	Holdings []HoldingsCheck `json:"holdings,omitempty"`
+}
+
+// HoldingsCheck is a holdings entry compared to a record, with the reason,
+// why the entry does not cover the record, e.g. after moving wall.
+type HoldingsCheck struct {
+	Key   string          `json:"key"` // ISSN, ISBN, DOI, database or title
+	Entry licensing.Entry `json:"entry"`
+	Err   string          `json:"err,omitempty"`
+}
+
+// Explainer is implemented by filters, that can explain their result, e.g.
+// filters combining other filters.
+type Explainer interface {
+	Explain(finc.IntermediateSchema) *Explanation
+}
+
+// filterName returns the configuration name of a filter, e.g. issn for an
+// ISSNFilter.
+func filterName(f Filter) string {
+	t := reflect.TypeOf(f)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return strings.ToLower(strings.TrimSuffix(t.Name(), "Filter"))
+}
+
+// Explain evaluates a filter for a record and returns the evaluated tree.
+// Filters, that do not implement Explainer, are leaves.
+func Explain(f Filter, is finc.IntermediateSchema) *Explanation {
+	if e, ok := f.(Explainer); ok {
+		return e.Explain(is)
+	}
+	return &Explanation{Filter: filterName(f), Result: f.Apply(is)}
+}
+
+// Explain evaluates all filters, not only up to the first match.
+func (f *OrFilter) Explain(is finc.IntermediateSchema) *Explanation {
+	e := &Explanation{Filter: "or"}
+	for _, f := range f.Filters {
+		c := Explain(f, is)
+		e.Result = e.Result || c.Result
+		e.Children = append(e.Children, c)
+	}
+	return e
+}
+
+// Explain evaluates all filters, not only up to the first mismatch.
+func (f *AndFilter) Explain(is finc.IntermediateSchema) *Explanation {
+	e := &Explanation{Filter: "and", Result: true}
+	for _, f := range f.Filters {
+		c := Explain(f, is)
+		e.Result = e.Result && c.Result
+		e.Children = append(e.Children, c)
+	}
+	return e
+}
+
+// Explain explains the inverted filter.
+func (f *NotFilter) Explain(is finc.IntermediateSchema) *Explanation {
+	c := Explain(f.Filter, is)
+	return &Explanation{Filter: "not", Result: !c.Result, Children: []*Explanation{c}}
+}
+
+// Explain lists all entries checked, with the reason, why an entry does not
+// cover the record.
+func (f *HoldingsFilter) Explain(is finc.IntermediateSchema) *Explanation {
+	e := &Explanation{Filter: "holdings"}
+	f.lookup(is, func(key string, entry licensing.Entry) bool {
+		c := HoldingsCheck{Key: key, Entry: entry}
+		if err := entry.Covers(is.RawDate, is.Volume, is.Issue); err != nil {
+			c.Err = err.Error()
+		} else {
+			e.Result = true
+		}
+		e.Holdings = append(e.Holdings, c)
+		return false
+	})
+	return e
+}
+
+// Explain explains the root filter.
+func (t *Tree) Explain(is finc.IntermediateSchema) *Explanation {
+	return Explain(t.Root, is)
+}
+
+// RecordExplanation holds the evaluated filter trees of a record by label.
+type RecordExplanation struct {
+	ID     string                  `json:"id"`
+	Labels map[string]*Explanation `json:"labels"`
+}
+
+// Explain evaluates the filters of all labels for a record, without tagging
+// it.
+func (t *Tagger) Explain(is finc.IntermediateSchema) RecordExplanation {
+	r := RecordExplanation{ID: is.ID, Labels: make(map[string]*Explanation)}
+	for tag, filter := range t.FilterMap {
+		r.Labels[tag] = filter.Explain(is)
+	}
+	return r
+}
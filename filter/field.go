@@ -0,0 +1,151 @@
+package filter
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/miku/span/container"
+	"github.com/miku/span/formats/finc"
+)
+
+// FieldFilter allows records by the value of an arbitrary field, addressed
+// by its JSON key, e.g. rft.pub, languages, x.oa or finc.format. All given
+// predicates must hold for a single value, list-valued fields match, if any
+// value does:
+//
+//     {"field": {"key": "rft.pub", "equals": "Elsevier"}}
+//     {"field": {"key": "languages", "in": ["eng", "ger"]}}
+//     {"field": {"key": "finc.format", "regex": "^ElectronicArticle$"}}
+//     {"field": {"key": "doi", "prefix": "10.1007/"}}
+//     {"field": {"key": "x.oa", "exists": true}}
+//     {"field": {"key": "rft.volume", "gte": 10, "lt": 20}}
+//
+// Numeric comparisons fail for values, that are not numbers.
+type FieldFilter struct {
+	Key    string
+	Equals *string
+	In     *container.StringSet
+	Regex  *regexp.Regexp
+	Prefix string
+	Exists *bool
+	Gt     *float64
+	Gte    *float64
+	Lt     *float64
+	Lte    *float64
+}
+
+// numeric returns true, if any numeric comparison is configured.
+func (f *FieldFilter) numeric() bool {
+	return f.Gt != nil || f.Gte != nil || f.Lt != nil || f.Lte != nil
+}
+
+// match returns true, if a single value satisfies all predicates.
+func (f *FieldFilter) match(v string) bool {
+	if f.Equals != nil && v != *f.Equals {
+		return false
+	}
+	if f.In != nil && !f.In.Contains(v) {
+		return false
+	}
+	if f.Regex != nil && !f.Regex.MatchString(v) {
+		return false
+	}
+	if f.Prefix != "" && !strings.HasPrefix(v, f.Prefix) {
+		return false
+	}
+	if f.numeric() {
+		x, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return false
+		}
+		switch {
+		case f.Gt != nil && x <= *f.Gt:
+			return false
+		case f.Gte != nil && x < *f.Gte:
+			return false
+		case f.Lt != nil && x >= *f.Lt:
+			return false
+		case f.Lte != nil && x > *f.Lte:
+			return false
+		}
+	}
+	return true
+}
+
+// Apply filter.
+func (f *FieldFilter) Apply(is finc.IntermediateSchema) bool {
+	values, err := is.Field(f.Key)
+	if err != nil {
+		return false
+	}
+	if f.Exists != nil {
+		if *f.Exists != (len(values) > 0) {
+			return false
+		}
+		if !*f.Exists {
+			return true
+		}
+	}
+	for _, v := range values {
+		if f.match(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// UnmarshalJSON turns a config fragment into a filter. Unknown keys and
+// invalid patterns are errors.
+func (f *FieldFilter) UnmarshalJSON(p []byte) error {
+	var s struct {
+		Field struct {
+			Key    string   `json:"key"`
+			Equals *string  `json:"equals"`
+			In     []string `json:"in"`
+			Regex  string   `json:"regex"`
+			Prefix string   `json:"prefix"`
+			Exists *bool    `json:"exists"`
+			Gt     *float64 `json:"gt"`
+			Gte    *float64 `json:"gte"`
+			Lt     *float64 `json:"lt"`
+			Lte    *float64 `json:"lte"`
+		} `json:"field"`
+	}
+	if err := json.Unmarshal(p, &s); err != nil {
+		return err
+	}
+	if _, err := new(finc.IntermediateSchema).Field(s.Field.Key); err != nil {
+		return fmt.Errorf("field filter: %v", err)
+	}
+	*f = FieldFilter{
+		Key:    s.Field.Key,
+		Equals: s.Field.Equals,
+		Prefix: s.Field.Prefix,
+		Exists: s.Field.Exists,
+		Gt:     s.Field.Gt,
+		Gte:    s.Field.Gte,
+		Lt:     s.Field.Lt,
+		Lte:    s.Field.Lte,
+	}
+	if s.Field.In != nil {
+		f.In = container.NewStringSet(s.Field.In...)
+	}
+	if s.Field.Regex != "" {
+		re, err := regexp.Compile(s.Field.Regex)
+		if err != nil {
+			return fmt.Errorf("field filter: %v", err)
+		}
+		f.Regex = re
+	}
+	predicates := f.Equals != nil || f.In != nil || f.Regex != nil || f.Prefix != "" || f.numeric()
+	switch {
+	case f.Exists != nil && !*f.Exists && predicates:
+		return fmt.Errorf("field filter: exists false cannot be combined with other predicates")
+	case f.Exists == nil && !predicates:
+		return fmt.Errorf("field filter: no predicate given for %s", f.Key)
+	}
+	return nil
+}
@@ -119,6 +119,18 @@ func unmarshalFilter(name string, raw json.RawMessage) (Filter, error) {
 			return nil, err
 		}
 		return &filter, nil
+	case "date":
+		var filter DateFilter
+		if err := json.Unmarshal(raw, &filter); err != nil {
+			return nil, err
+		}
+		return &filter, nil
+	case "field":
+		var filter FieldFilter
+		if err := json.Unmarshal(raw, &filter); err != nil {
+			return nil, err
+		}
+		return &filter, nil
 	case "subject":
 		var filter SubjectFilter
 		if err := json.Unmarshal(raw, &filter); err != nil {
@@ -2,7 +2,9 @@ package filter
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/miku/span/formats/finc"
 )
@@ -181,3 +183,190 @@ func TestNotFilter1(t *testing.T) {
 		}
 	}
 }
+
+func TestExplain(t *testing.T) {
+	s := `{"and": [{"or": [{"source": ["1"]}, {"collection": ["A"]}]}, {"not": {"doi": {"list": ["10.1/x"]}}}]}`
+	var tree Tree
+	if err := json.Unmarshal([]byte(s), &tree); err != nil {
+		t.Fatalf("invalid filter: %s", err)
+	}
+	is := finc.IntermediateSchema{SourceID: "2", MegaCollections: []string{"A"}, DOI: "10.1/x"}
+	e := tree.Explain(is)
+	if e.Result != tree.Apply(is) {
+		t.Errorf("Explain got %v, Apply %v", e.Result, tree.Apply(is))
+	}
+	b, err := json.Marshal(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"filter":"and","result":false,"children":[` +
+		`{"filter":"or","result":true,"children":[{"filter":"source","result":false},{"filter":"collection","result":true}]},` +
+		`{"filter":"not","result":false,"children":[{"filter":"doi","result":true}]}]}`
+	if string(b) != want {
+		t.Errorf("Explain got %s, want %s", b, want)
+	}
+}
+
+func TestDateFilter(t *testing.T) {
+	now = func() time.Time { return time.Date(2018, 6, 15, 0, 0, 0, 0, time.UTC) }
+	defer func() { now = time.Now }()
+
+	var tests = []struct {
+		filter string
+		date   string
+		result bool
+	}{
+		{`{"date": {"from": "2010", "until": "2020"}}`, "2010-01-01", true},
+		{`{"date": {"from": "2010", "until": "2020"}}`, "2020-12-31", true},
+		{`{"date": {"from": "2010", "until": "2020"}}`, "2021", false},
+		{`{"date": {"from": "2010", "until": "2020"}}`, "", false},
+		{`{"date": {"after": "2010", "before": "2020"}}`, "2010-06-01", false},
+		{`{"date": {"after": "2010", "before": "2020"}}`, "2011", true},
+		{`{"date": {"from": "2010-04-15"}}`, "2010-04-14", false},
+		{`{"date": {"from": "2010-04-15"}}`, "2010-04", true},
+		{`{"date": {"before": "P2Y"}}`, "2016-01", true},
+		{`{"date": {"before": "P2Y"}}`, "2016-12-31", false},
+		{`{"date": {"from": "P6M"}}`, "2017-12", true},
+		{`{"date": {"from": "P6M"}}`, "2017-11-30", false},
+		{`{"not": {"date": {"until": "2000"}}}`, "2001", true},
+	}
+	for _, test := range tests {
+		var tree Tree
+		if err := json.Unmarshal([]byte(test.filter), &tree); err != nil {
+			t.Fatalf("invalid filter: %s", err)
+		}
+		if result := tree.Apply(finc.IntermediateSchema{RawDate: test.date}); result != test.result {
+			t.Errorf("%s on %q: got %v, want %v", test.filter, test.date, result, test.result)
+		}
+	}
+	for _, s := range []string{`{"date": {}}`, `{"date": {"from": "abc"}}`, `{"date": {"before": "P2W"}}`} {
+		var tree Tree
+		if err := json.Unmarshal([]byte(s), &tree); err == nil {
+			t.Errorf("%s: expected error", s)
+		}
+	}
+}
+
+func TestFieldFilter(t *testing.T) {
+	is := finc.IntermediateSchema{
+		Publishers: []string{"Springer", "Elsevier"},
+		Languages:  []string{"eng"},
+		Format:     "ElectronicArticle",
+		DOI:        "10.1007/123",
+		Volume:     "12",
+		OpenAccess: true,
+	}
+	var tests = []struct {
+		filter string
+		result bool
+	}{
+		{`{"field": {"key": "rft.pub", "equals": "Elsevier"}}`, true},
+		{`{"field": {"key": "rft.pub", "equals": "Wiley"}}`, false},
+		{`{"field": {"key": "languages", "in": ["ger", "eng"]}}`, true},
+		{`{"field": {"key": "languages", "in": ["ger"]}}`, false},
+		{`{"field": {"key": "finc.format", "regex": "^Electronic"}}`, true},
+		{`{"field": {"key": "finc.format", "regex": "Book$"}}`, false},
+		{`{"field": {"key": "doi", "prefix": "10.1007/"}}`, true},
+		{`{"field": {"key": "x.oa", "exists": true}}`, true},
+		{`{"field": {"key": "x.oa", "equals": "true"}}`, true},
+		{`{"field": {"key": "rft.issue", "exists": false}}`, true},
+		{`{"field": {"key": "rft.issue", "exists": true}}`, false},
+		{`{"field": {"key": "rft.volume", "gte": 10, "lt": 20}}`, true},
+		{`{"field": {"key": "rft.volume", "gt": 12}}`, false},
+		{`{"field": {"key": "rft.pub", "lt": 20}}`, false},
+		{`{"field": {"key": "rft.pub", "prefix": "Spr", "regex": "er$"}}`, true},
+		{`{"field": {"key": "rft.pub", "prefix": "Spr", "regex": "^E"}}`, false},
+	}
+	for _, test := range tests {
+		var tree Tree
+		if err := json.Unmarshal([]byte(test.filter), &tree); err != nil {
+			t.Fatalf("invalid filter: %s", err)
+		}
+		if result := tree.Apply(is); result != test.result {
+			t.Errorf("%s: got %v, want %v", test.filter, result, test.result)
+		}
+	}
+	for _, s := range []string{
+		`{"field": {"key": "rft.unknown", "exists": true}}`,
+		`{"field": {"key": "rft.pub"}}`,
+		`{"field": {"key": "rft.pub", "regex": "("}}`,
+		`{"field": {"key": "rft.pub", "exists": false, "equals": "X"}}`,
+	} {
+		var tree Tree
+		if err := json.Unmarshal([]byte(s), &tree); err == nil {
+			t.Errorf("%s: expected error", s)
+		}
+	}
+}
+
+func TestHoldingsFilterBooks(t *testing.T) {
+	kbart := "publication_title\tprint_identifier\tonline_identifier\tdate_first_issue_online\t" +
+		"num_first_vol_online\tnum_first_issue_online\tdate_last_issue_online\tnum_last_vol_online\t" +
+		"num_last_issue_online\ttitle_url\tfirst_author\ttitle_id\tembargo_info\tcoverage_depth\n" +
+		"A Book\t0-306-40615-2\t\t\t\t\t\t\t\t\t\t\t\tebook\n" +
+		"Another Book\t\t\t\t\t\t\t\t\thttp://link.springer.com/10.1007/978-3-658-10838-0\t\t10.1007/978-3-658-10838-0\t\tebook\n"
+	key := "test-books.tsv"
+	if err := Cache.register(key, strings.NewReader(kbart)); err != nil {
+		t.Fatal(err)
+	}
+	defer delete(Cache, key)
+	f := &HoldingsFilter{Names: []string{key}}
+	if n := f.count(); n != 2 {
+		t.Errorf("count: got %d, want 2", n)
+	}
+
+	var tests = []struct {
+		is     finc.IntermediateSchema
+		result bool
+	}{
+		{finc.IntermediateSchema{ISBN: []string{"978-0-306-40615-7"}, RawDate: "2010"}, true},
+		{finc.IntermediateSchema{EISBN: []string{"0306406152"}, RawDate: "2010"}, true},
+		{finc.IntermediateSchema{ISBN: []string{"978-3-16-148410-0"}, RawDate: "2010"}, false},
+		{finc.IntermediateSchema{DOI: "10.1007/978-3-658-10838-0", RawDate: "2015"}, true},
+		{finc.IntermediateSchema{DOI: "10.1007/978-3-658-10838-0_5", RawDate: "2015"}, true},
+		{finc.IntermediateSchema{DOI: "10.1007/978-3-658-10839-0_5", RawDate: "2015"}, false},
+		{finc.IntermediateSchema{DOI: "10.1007/978-3-658-10838-0_5"}, false},
+	}
+	for _, test := range tests {
+		if result := f.Apply(test.is); result != test.result {
+			t.Errorf("Apply(%v %v %v): got %v, want %v", test.is.ISBN, test.is.EISBN, test.is.DOI, result, test.result)
+		}
+		if e := f.Explain(test.is); e.Result != test.result {
+			t.Errorf("Explain(%v %v %v): got %v, want %v", test.is.ISBN, test.is.EISBN, test.is.DOI, e.Result, test.result)
+		}
+	}
+}
+
+func TestHoldingsFilterDatabase(t *testing.T) {
+	kbart := "publication_title\tprint_identifier\tonline_identifier\tdate_first_issue_online\t" +
+		"num_first_vol_online\tnum_first_issue_online\tdate_last_issue_online\tnum_last_vol_online\t" +
+		"num_last_issue_online\ttitle_url\tfirst_author\ttitle_id\tembargo_info\tcoverage_depth\n" +
+		"Aargauer Zeitung\t\t\t2005\t\t\t\t\t\thttps://www.wiso-net.de/dosearch?&dbShortcut=AGZ\t\t\t\tVolltext\n" +
+		"Berliner Zeitung\t\t\t2005\t\t\t\t\t\thttps://www.wiso-net.de/dosearch?&dbShortcut=BEZ\t\t\t\tVolltext\n" +
+		"A Journal\t1234-5679\t2049-3630\t2000\t\t\t\t\t\t\t\t\t\tVolltext\n"
+	key := "test-wiso.tsv"
+	if err := Cache.register(key, strings.NewReader(kbart)); err != nil {
+		t.Fatal(err)
+	}
+	defer delete(Cache, key)
+	if n := (&HoldingsFilter{Names: []string{key}}).count(); n != 3 {
+		t.Errorf("count: got %d, want 3", n)
+	}
+
+	var tests = []struct {
+		compare bool
+		is      finc.IntermediateSchema
+		result  bool
+	}{
+		{true, finc.IntermediateSchema{Database: "AGZ", RawDate: "2010-01-01"}, true},
+		{true, finc.IntermediateSchema{Database: "AGZ", RawDate: "2001-01-01"}, false},
+		{true, finc.IntermediateSchema{Database: "XYZ", RawDate: "2010-01-01"}, false},
+		{false, finc.IntermediateSchema{Database: "AGZ", RawDate: "2010-01-01"}, false},
+	}
+	for _, test := range tests {
+		f := &HoldingsFilter{Names: []string{key}, CompareByDatabase: test.compare}
+		if result := f.Apply(test.is); result != test.result {
+			t.Errorf("Apply(%s, %s, %v): got %v, want %v", test.is.Database, test.is.RawDate, test.compare, result, test.result)
+		}
+	}
+}
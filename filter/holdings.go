@@ -20,13 +20,15 @@ type CacheValue struct {
 	SerialNumberMap map[string][]licensing.Entry `json:"s"` // key: ISSN
 	WisoDatabaseMap map[string][]licensing.Entry `json:"w"` // key: WISO DB name
 	TitleMap        map[string][]licensing.Entry `json:"t"` // key: publication title
+	ISBNMap         map[string][]licensing.Entry `json:"i"` // key: ISBN-13
+	DOIMap          map[string][]licensing.Entry `json:"d"` // key: lowercase DOI of title
 }
 
 // HoldingsCache caches items keyed by filename or url. A configuration might
 // refer to the same holding file hundreds or thousands of times, but we only
 // want to store the content once. This map serves as a private singleton that
 // holds licensing entries and precomputed shortcuts to find relevant entries
-// (rows from KBART) by ISSN, wiso database name or title.
+// (rows from KBART) by ISSN, ISBN, DOI, wiso database name or title.
 type HoldingsCache map[string]CacheValue
 
 // register reads a holding file from a reader and caches it under the given
@@ -45,6 +47,8 @@ func (c *HoldingsCache) register(key string, r io.Reader) error {
 		SerialNumberMap: h.SerialNumberMap(),
 		WisoDatabaseMap: h.WisoDatabaseMap(),
 		TitleMap:        h.TitleMap(),
+		ISBNMap:         h.ISBNMap(),
+		DOIMap:          h.DOIMap(),
 	}
 	if rc, ok := r.(io.Closer); ok {
 		return rc.Close()
@@ -88,27 +92,43 @@ type HoldingsFilter struct {
 	Verbose bool     `json:"verbose,omitempty"`
 	// Beside ISSN, also try to compare by title, this is fuzzy, so disabled by default.
 	CompareByTitle bool `json:"compare-by-title,omitempty"`
+	// Also compare the database name of a record (ris.db) to WISO database
+	// names found in title URLs, for Genios, refs. #9534.
+	CompareByDatabase bool `json:"compare-by-database,omitempty"`
 	// Allow direct access to entries, might replace Names.
 	CachedValues map[string]*CacheValue `json:"cache,omitempty"`
 }
 
-// count returns the number of entries loaded for this filter.
-func (f *HoldingsFilter) count() (count int) {
+// count returns the number of distinct entries loaded for this filter. An
+// entry is usually found under more than one key, e.g. print and online
+// ISSN and title, or only in the ISBN or DOI maps.
+func (f *HoldingsFilter) count() int {
+	seen := make(map[licensing.Entry]bool)
 	for _, name := range f.Names {
-		count += len(Cache[name].SerialNumberMap)
+		v := Cache[name]
+		for _, m := range []map[string][]licensing.Entry{
+			v.SerialNumberMap, v.WisoDatabaseMap, v.TitleMap, v.ISBNMap, v.DOIMap,
+		} {
+			for _, entries := range m {
+				for _, entry := range entries {
+					seen[entry] = true
+				}
+			}
+		}
 	}
-	return
+	return len(seen)
 }
 
 // UnmarshalJSON deserializes this filter.
 func (f *HoldingsFilter) UnmarshalJSON(p []byte) error {
 	var s struct {
 		Holdings struct {
-			Filename       string   `json:"file"` // compat
-			Filenames      []string `json:"files"`
-			Links          []string `json:"urls"`
-			Verbose        bool     `json:"verbose"`
-			CompareByTitle bool     `json:"compare-by-title"`
+			Filename          string   `json:"file"` // compat
+			Filenames         []string `json:"files"`
+			Links             []string `json:"urls"`
+			Verbose           bool     `json:"verbose"`
+			CompareByTitle    bool     `json:"compare-by-title"`
+			CompareByDatabase bool     `json:"compare-by-database"`
 		} `json:"holdings"`
 	}
 	if err := json.Unmarshal(p, &s); err != nil {
@@ -144,6 +164,7 @@ func (f *HoldingsFilter) UnmarshalJSON(p []byte) error {
 
 	f.Verbose = s.Holdings.Verbose
 	f.CompareByTitle = s.Holdings.CompareByTitle
+	f.CompareByDatabase = s.Holdings.CompareByDatabase
 
 	if f.CachedValues == nil {
 		f.CachedValues = make(map[string]*CacheValue)
@@ -171,17 +192,66 @@ func (f *HoldingsFilter) covers(entry licensing.Entry, is finc.IntermediateSchem
 	return false
 }
 
-// Apply returns true, if there is a valid holding for a given record. This will
-// take multiple attributes like date, volume, issue and embargo into account. This
-// function is very specific: it works only with intermediate format and it uses specific
-// information from that format to decide on attachment.
-func (f *HoldingsFilter) Apply(is finc.IntermediateSchema) bool {
-	// By default test serial number.
+// doiPrefixes returns the lowercase DOI and its prefixes, that end before a
+// separator, longest first, e.g. 10.1007/978-3-658-10838-0_5 and
+// 10.1007/978-3-658-10838-0 and so on, down to the first character of the
+// suffix.
+func doiPrefixes(doi string) (prefixes []string) {
+	doi = strings.ToLower(strings.TrimSpace(doi))
+	i := strings.Index(doi, "/")
+	if i < 0 || i == len(doi)-1 {
+		return nil
+	}
+	prefixes = append(prefixes, doi)
+	for j := len(doi) - 1; j > i+1; j-- {
+		if strings.ContainsRune("_-./", rune(doi[j])) {
+			prefixes = append(prefixes, doi[:j])
+		}
+	}
+	return prefixes
+}
+
+// lookup calls a function for candidate entries of a record, found by ISSN,
+// ISBN, DOI (or DOI prefix) and optionally database name and title, until the
+// function returns true.
+func (f *HoldingsFilter) lookup(is finc.IntermediateSchema, fn func(key string, entry licensing.Entry) bool) bool {
 	for _, issn := range append(is.ISSN, is.EISSN...) {
 		for _, key := range f.Names {
-			item := Cache[key]
-			for _, entry := range item.SerialNumberMap[issn] {
-				if f.covers(entry, is) {
+			for _, entry := range Cache[key].SerialNumberMap[issn] {
+				if fn(issn, entry) {
+					return true
+				}
+			}
+		}
+	}
+	// E-books, refs. KBART print and online identifier.
+	for _, isbn := range is.ISBNList() {
+		if isbn = licensing.NormalizeISBN(isbn); isbn == "" {
+			continue
+		}
+		for _, key := range f.Names {
+			for _, entry := range Cache[key].ISBNMap[isbn] {
+				if fn(isbn, entry) {
+					return true
+				}
+			}
+		}
+	}
+	// Books and chapters, refs. KBART title_id.
+	for _, doi := range doiPrefixes(is.DOI) {
+		for _, key := range f.Names {
+			for _, entry := range Cache[key].DOIMap[doi] {
+				if fn(doi, entry) {
+					return true
+				}
+			}
+		}
+	}
+	// Optionally test by WISO database name, e.g. for Genios.
+	if f.CompareByDatabase && is.Database != "" {
+		for _, key := range f.Names {
+			for _, entry := range Cache[key].WisoDatabaseMap[is.Database] {
+				if fn(is.Database, entry) {
 					return true
 				}
 			}
@@ -190,9 +260,8 @@ func (f *HoldingsFilter) Apply(is finc.IntermediateSchema) bool {
 	// Optionally test by title, refs. #10707.
 	if f.CompareByTitle {
 		for _, key := range f.Names {
-			item := Cache[key]
-			for _, entry := range item.TitleMap[is.ArticleTitle] {
-				if f.covers(entry, is) {
+			for _, entry := range Cache[key].TitleMap[is.ArticleTitle] {
+				if fn(is.ArticleTitle, entry) {
 					return true
 				}
 			}
@@ -200,3 +269,14 @@ func (f *HoldingsFilter) Apply(is finc.IntermediateSchema) bool {
 	}
 	return false
 }
+
+// Apply returns true, if there is a valid holding for a given record. This will
+// take multiple attributes like date, volume, issue and embargo into account. This
+// function is very specific: it works only with intermediate format and it uses specific
+// information from that format to decide on attachment. Records are matched by
+// ISSN, ISBN, DOI and optionally by database name and title.
+func (f *HoldingsFilter) Apply(is finc.IntermediateSchema) bool {
+	return f.lookup(is, func(_ string, entry licensing.Entry) bool {
+		return f.covers(entry, is)
+	})
+}
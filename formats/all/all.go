@@ -0,0 +1,29 @@
+// Package all registers all input formats. Import it for side effects only:
+//
+//     import _ "github.com/miku/span/formats/all"
+//
+// A new format package needs to be added to the import list below.
+package all
+
+import (
+	_ "github.com/miku/span/formats/ceeol"
+	_ "github.com/miku/span/formats/crossref"
+	_ "github.com/miku/span/formats/degruyter"
+	_ "github.com/miku/span/formats/disson"
+	_ "github.com/miku/span/formats/doaj"
+	_ "github.com/miku/span/formats/dummy"
+	_ "github.com/miku/span/formats/elsevier"
+	_ "github.com/miku/span/formats/genderopen"
+	_ "github.com/miku/span/formats/genios"
+	_ "github.com/miku/span/formats/hhbd"
+	_ "github.com/miku/span/formats/highwire"
+	_ "github.com/miku/span/formats/ieee"
+	_ "github.com/miku/span/formats/imslp"
+	_ "github.com/miku/span/formats/jats"
+	_ "github.com/miku/span/formats/jstor"
+	_ "github.com/miku/span/formats/marc"
+	_ "github.com/miku/span/formats/olms"
+	_ "github.com/miku/span/formats/ssoar"
+	_ "github.com/miku/span/formats/thieme"
+	_ "github.com/miku/span/formats/zvdd"
+)
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/miku/span"
+	"github.com/miku/span/formats"
 	"github.com/miku/span/formats/finc"
 	"github.com/shantanubhadoria/go-roman/roman"
 )
@@ -20,6 +21,17 @@ const (
 	Collection       = "CEEOL Central and Eastern European Online Library"
 )
 
+func init() {
+	formats.Register(formats.Format{
+		Name:        "ceeol",
+		Shape:       formats.XML,
+		New:         func() interface{} { return new(Article) },
+		SourceID:    SourceIdentifier,
+		Collection:  Collection,
+		Description: "CEEOL Central and Eastern European Online Library",
+	})
+}
+
 // Article from CEEOL, refs #9398.
 type Article struct {
 	XMLName                 xml.Name `xml:"Article"`
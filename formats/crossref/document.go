@@ -26,12 +26,14 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/miku/span"
 	"github.com/miku/span/assetutil"
+	"github.com/miku/span/formats"
 	"github.com/miku/span/formats/finc"
 )
 
@@ -40,6 +42,16 @@ const (
 	SourceID = "49"
 )
 
+func init() {
+	formats.Register(formats.Format{
+		Name:        "crossref",
+		Shape:       formats.NDJSON,
+		New:         func() interface{} { return new(Document) },
+		SourceID:    SourceID,
+		Description: "CrossRef API works, one per line",
+	})
+}
+
 var (
 	errNoDate = errors.New("date is missing")
 	errNoURL  = errors.New("URL is missing")
@@ -90,34 +102,78 @@ type DateField struct {
 	Timestamp int64      `json:"timestamp"`
 }
 
+// Contributor is an author, editor or translator.
+type Contributor struct {
+	Family      string `json:"family"`
+	Given       string `json:"given"`
+	Name        string `json:"name"`
+	ORCID       string `json:"ORCID"`
+	Affiliation []struct {
+		Name string `json:"name"`
+		ID   []struct {
+			ID     string `json:"id"`
+			IDType string `json:"id-type"`
+		} `json:"id"`
+	} `json:"affiliation"`
+}
+
+// Event is the conference, for proceedings articles.
+type Event struct {
+	Name     string    `json:"name"`
+	Acronym  string    `json:"acronym"`
+	Number   string    `json:"number"`
+	Location string    `json:"location"`
+	Start    DateField `json:"start"`
+	End      DateField `json:"end"`
+}
+
+// Update is an entry of update-to or updated-by, e.g. a retraction.
+type Update struct {
+	DOI     string    `json:"DOI"`
+	Type    string    `json:"type"`
+	Label   string    `json:"label"`
+	Updated DateField `json:"updated"`
+}
+
+// RelatedItem is the target of a relation.
+type RelatedItem struct {
+	ID         string `json:"id"`
+	IDType     string `json:"id-type"`
+	AssertedBy string `json:"asserted-by"`
+}
+
 // Document is a example 'works' API response - message part only.
 type Document struct {
-	Abstract string `json:"abstract"`
-	Author   []struct {
-		Family string `json:"family"`
-		Given  string `json:"given"`
-	} `json:"author"`
-	ContainerTitle []string  `json:"container-title"`
-	Deposited      DateField `json:"deposited"`
-	DOI            string    `json:"DOI"`
-	Indexed        DateField `json:"indexed"`
-	ISSN           []string  `json:"ISSN"`
-	Issue          string    `json:"issue"`
-	Issued         DateField `json:"issued"`
-	Member         string    `json:"member"`
-	Page           string    `json:"page"`
-	Prefix         string    `json:"prefix"`
-	PublishedPrint DateField `json:"published-print"`
-	Publisher      string    `json:"publisher"`
-	ReferenceCount int       `json:"reference-count"`
-	Score          float64   `json:"score"`
-	Source         string    `json:"source"`
-	Subjects       []string  `json:"subject"`
-	Subtitle       []string  `json:"subtitle"`
-	Title          []string  `json:"title"`
-	Type           string    `json:"type"`
-	URL            string    `json:"URL"`
-	Volume         string    `json:"volume"`
+	Abstract       string                   `json:"abstract"`
+	Author         []Contributor            `json:"author"`
+	Editor         []Contributor            `json:"editor"`
+	Event          *Event                   `json:"event"`
+	Translator     []Contributor            `json:"translator"`
+	ContainerTitle []string                 `json:"container-title"`
+	Deposited      DateField                `json:"deposited"`
+	DOI            string                   `json:"DOI"`
+	Indexed        DateField                `json:"indexed"`
+	ISSN           []string                 `json:"ISSN"`
+	Issue          string                   `json:"issue"`
+	ISBN           []string                 `json:"ISBN"`
+	Issued         DateField                `json:"issued"`
+	Member         string                   `json:"member"`
+	Page           string                   `json:"page"`
+	Prefix         string                   `json:"prefix"`
+	PublishedPrint DateField                `json:"published-print"`
+	Publisher      string                   `json:"publisher"`
+	ReferenceCount int                      `json:"reference-count"`
+	Relation       map[string][]RelatedItem `json:"relation"`
+	Score          float64                  `json:"score"`
+	Source         string                   `json:"source"`
+	Subjects       []string                 `json:"subject"`
+	Subtitle       []string                 `json:"subtitle"`
+	Title          []string                 `json:"title"`
+	Type           string                   `json:"type"`
+	UpdateTo       []Update                 `json:"update-to"`
+	UpdatedBy      []Update                 `json:"updated-by"`
+	URL            string                   `json:"URL"`
+	Volume         string                   `json:"volume"`
 }
 
 // PageInfo holds various page related data.
@@ -139,12 +195,38 @@ func (pi *PageInfo) PageCount() int {
 	return 0
 }
 
+// Authors returns authors, editors and translators, with ORCID and
+// affiliations.
 func (doc *Document) Authors() (authors []finc.Author) {
-	for _, ra := range doc.Author {
-		authors = append(authors, finc.Author{
-			FirstName: AuthorReplacer.Replace(span.UnescapeTrim(ra.Given)),
-			LastName:  AuthorReplacer.Replace(span.UnescapeTrim(ra.Family)),
-		})
+	for _, g := range []struct {
+		role         string
+		contributors []Contributor
+	}{
+		{"", doc.Author},
+		{finc.RoleEditor, doc.Editor},
+		{finc.RoleTranslator, doc.Translator},
+	} {
+		for _, ra := range g.contributors {
+			author := finc.Author{
+				FirstName: AuthorReplacer.Replace(span.UnescapeTrim(ra.Given)),
+				LastName:  AuthorReplacer.Replace(span.UnescapeTrim(ra.Family)),
+				Role:      g.role,
+				ORCID:     finc.NormalizeORCID(ra.ORCID),
+			}
+			if author.LastName == "" && author.FirstName == "" {
+				author.Name = span.UnescapeTrim(ra.Name)
+			}
+			for _, aff := range ra.Affiliation {
+				affiliation := finc.Affiliation{Name: span.UnescapeTrim(aff.Name)}
+				for _, id := range aff.ID {
+					if strings.EqualFold(id.IDType, "ROR") {
+						affiliation.ROR = finc.NormalizeROR(id.ID)
+					}
+				}
+				author.Affiliations = append(author.Affiliations, affiliation)
+			}
+			authors = append(authors, author)
+		}
 	}
 	return authors
 }
@@ -201,6 +283,103 @@ func (d *DateField) Date() (t time.Time, err error) {
 	return time.Parse("2006-01-02", ds)
 }
 
+// ISODate returns the date with the available precision, e.g. 2017-12-05,
+// 2017-12 or 2017, or an empty string.
+func (d *DateField) ISODate() string {
+	if len(d.DateParts) == 0 {
+		return ""
+	}
+	parts := d.DateParts[0]
+	switch {
+	case len(parts) == 0 || parts[0] == 0:
+		return ""
+	case len(parts) == 1:
+		return fmt.Sprintf("%04d", parts[0])
+	case len(parts) == 2:
+		return fmt.Sprintf("%04d-%02d", parts[0], parts[1])
+	}
+	return fmt.Sprintf("%04d-%02d-%02d", parts[0], parts[1], parts[2])
+}
+
+// FincEvent converts the event, it returns nil for a missing or empty event.
+func (e *Event) FincEvent() *finc.Event {
+	if e == nil {
+		return nil
+	}
+	event := &finc.Event{
+		Name:      span.UnescapeTrim(e.Name),
+		Acronym:   strings.TrimSpace(e.Acronym),
+		Number:    strings.TrimSpace(e.Number),
+		Place:     strings.TrimSpace(e.Location),
+		StartDate: e.Start.ISODate(),
+		EndDate:   e.End.ISODate(),
+	}
+	if event.IsZero() {
+		return nil
+	}
+	return event
+}
+
+// Relations returns updates, like retractions or errata, relations and the
+// parent book of a book chapter.
+func (doc *Document) Relations() (relations []finc.Relation) {
+	for _, u := range doc.UpdateTo {
+		// A document may list itself as updated, e.g. for new versions.
+		if u.DOI == "" || strings.EqualFold(u.DOI, doc.DOI) {
+			continue
+		}
+		relations = append(relations, finc.Relation{
+			Type:   finc.UpdateRelation(u.Type, false),
+			ID:     u.DOI,
+			IDType: "doi",
+			Label:  u.Label,
+			Date:   u.Updated.ISODate(),
+		})
+	}
+	for _, u := range doc.UpdatedBy {
+		if u.DOI == "" || strings.EqualFold(u.DOI, doc.DOI) {
+			continue
+		}
+		relations = append(relations, finc.Relation{
+			Type:   finc.UpdateRelation(u.Type, true),
+			ID:     u.DOI,
+			IDType: "doi",
+			Label:  u.Label,
+			Date:   u.Updated.ISODate(),
+		})
+	}
+	var types []string
+	for typ := range doc.Relation {
+		types = append(types, typ)
+	}
+	sort.Strings(types)
+	for _, typ := range types {
+		for _, item := range doc.Relation[typ] {
+			if item.ID == "" {
+				continue
+			}
+			relations = append(relations, finc.Relation{
+				Type:   typ,
+				ID:     item.ID,
+				IDType: strings.ToLower(item.IDType),
+			})
+		}
+	}
+	if doc.Type == "book-chapter" || doc.Type == "book-section" || doc.Type == "book-part" {
+		parent := finc.Relation{Type: finc.RelationIsPartOf}
+		if len(doc.ContainerTitle) > 0 {
+			parent.Title = span.UnescapeTrim(doc.ContainerTitle[0])
+		}
+		if len(doc.ISBN) > 0 {
+			parent.ID, parent.IDType = doc.ISBN[0], "isbn"
+		}
+		if parent.ID != "" || parent.Title != "" {
+			relations = append(relations, parent)
+		}
+	}
+	return relations
+}
+
 // CombinedTitle returns a longish title.
 func (doc *Document) CombinedTitle() string {
 	if len(doc.Title) > 0 {
@@ -308,6 +487,8 @@ func (doc *Document) ToIntermediateSchema() (*finc.IntermediateSchema, error) {
 	}
 
 	output.Authors = doc.Authors()
+	output.Event = doc.Event.FincEvent()
+	output.Relations = doc.Relations()
 
 	// TODO(miku): do we need a config for these things?
 	// Maybe a generic filter (in js?) that will gather exclusion rules?
@@ -0,0 +1,67 @@
+package crossref
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/miku/span/formats/finc"
+)
+
+func TestRelations(t *testing.T) {
+	var want = map[string][]finc.Relation{
+		// Retraction notice, the self-reference is skipped, relations follow
+		// in order of their type.
+		"10.1016/j.notice.2020.1": {
+			{Type: finc.RelationRetracts, ID: "10.1016/j.orig.2019.1", IDType: "doi", Label: "Retraction", Date: "2020-03-04"},
+			{Type: "has-preprint", ID: "10.1101/2019.01.01.1", IDType: "doi"},
+			{Type: "has-review", ID: "10.3410/f.1", IDType: "doi"},
+			{Type: "has-review", ID: "10.3410/f.2", IDType: "doi"},
+			{Type: "is-supplemented-by", ID: "10.5061/dryad.x", IDType: "doi"},
+		},
+		// Retracted article, pointing back to the notice.
+		"10.1016/j.orig.2019.1": {
+			{Type: finc.RelationIsRetractedBy, ID: "10.1016/j.notice.2020.1", IDType: "doi", Label: "Retraction", Date: "2020-03-04"},
+			{Type: finc.RelationIsCorrectedBy, ID: "10.1016/j.err.2019.2", IDType: "doi", Label: "Erratum", Date: "2019-06"},
+		},
+		"10.1007/978-3-030-00001-1_1": {
+			{Type: finc.RelationIsPartOf, ID: "9783030000011", IDType: "isbn", Title: "Handbook of Things & Stuff"},
+		},
+		"10.1007/978-3-030-00002-8_2": {
+			{Type: finc.RelationIsPartOf, Title: "Handbook of Stuff"},
+		},
+		"10.1007/978-3-030-00003-5_3": nil,
+	}
+	f, err := os.Open("../../fixtures/crossref-relations.ldj")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var n int
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var doc Document
+		if err := json.Unmarshal(scanner.Bytes(), &doc); err != nil {
+			t.Fatal(err)
+		}
+		expected, ok := want[doc.DOI]
+		if !ok {
+			t.Fatalf("unexpected document in fixture: %s", doc.DOI)
+		}
+		// Relation is a map, repeat to catch unstable order.
+		for i := 0; i < 10; i++ {
+			if got := doc.Relations(); !reflect.DeepEqual(got, expected) {
+				t.Fatalf("%s: got %+v, want %+v", doc.DOI, got, expected)
+			}
+		}
+		n++
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if n != len(want) {
+		t.Errorf("got %d documents, want %d", n, len(want))
+	}
+}
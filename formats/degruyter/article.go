@@ -28,6 +28,7 @@ import (
 	"fmt"
 
 	"github.com/miku/span"
+	"github.com/miku/span/formats"
 	"github.com/miku/span/formats/finc"
 	"github.com/miku/span/formats/jats"
 )
@@ -41,6 +42,17 @@ const (
 	Format = "ElectronicArticle"
 )
 
+func init() {
+	formats.Register(formats.Format{
+		Name:        "degruyter",
+		Shape:       formats.XML,
+		New:         func() interface{} { return new(Article) },
+		SourceID:    SourceID,
+		Collection:  SourceName,
+		Description: "De Gruyter JATS",
+	})
+}
+
 // Article with extras for this source.
 type Article struct {
 	XMLName xml.Name `xml:"article"`
@@ -8,10 +8,29 @@ import (
 	"time"
 
 	"github.com/miku/span"
+	"github.com/miku/span/formats"
 	"github.com/miku/span/formats/finc"
 	"github.com/miku/span/formats/marc"
 )
 
+const (
+	// SourceID for internal bookkeeping.
+	SourceID = "13"
+	// Collection for finc.mega_collection.
+	Collection = "Diss online"
+)
+
+func init() {
+	formats.Register(formats.Format{
+		Name:        "disson",
+		Shape:       formats.XML,
+		New:         func() interface{} { return new(Record) },
+		SourceID:    SourceID,
+		Collection:  Collection,
+		Description: "Diss online, MARC XML via OAI",
+	})
+}
+
 type Record struct {
 	marc.Record
 }
@@ -47,12 +66,12 @@ func (r Record) FindYear() string {
 
 func (r Record) ToIntermediateSchema() (*finc.IntermediateSchema, error) {
 	output := finc.NewIntermediateSchema()
-	output.SourceID = "13"
+	output.SourceID = SourceID
 	output.RecordID = r.MustGetControlField("001")
 	output.ID = fmt.Sprintf("ai-%s-%s", output.SourceID, output.RecordID)
 	output.Format = "ElectronicThesis"
 	output.Genre = "book"
-	output.MegaCollections = []string{"Diss online"}
+	output.MegaCollections = []string{Collection}
 
 	output.Languages = r.MustGetDataFields("041.a")
 	output.URL = r.MustGetDataFields("856.u")
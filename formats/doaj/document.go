@@ -34,6 +34,7 @@ import (
 	"github.com/miku/span"
 	"github.com/miku/span/assetutil"
 	"github.com/miku/span/container"
+	"github.com/miku/span/formats"
 	"github.com/miku/span/formats/finc"
 )
 
@@ -45,6 +46,25 @@ const (
 	DefaultRefType   = "EJOUR"
 )
 
+func init() {
+	formats.Register(formats.Format{
+		Name:        "doaj",
+		Shape:       formats.NDJSON,
+		New:         func() interface{} { return new(Response) },
+		SourceID:    SourceIdentifier,
+		Collection:  Collection,
+		Description: "DOAJ elasticsearch export",
+	})
+	formats.Register(formats.Format{
+		Name:        "doaj-api",
+		Shape:       formats.NDJSON,
+		New:         func() interface{} { return new(ArticleV1) },
+		SourceID:    SourceIdentifier,
+		Collection:  Collection,
+		Description: "DOAJ API v1 articles",
+	})
+}
+
 var (
 	LCCPatterns = assetutil.MustLoadRegexpMap("assets/finc/lcc.json")
 	LanguageMap = assetutil.MustLoadStringMap("assets/doaj/language-iso-639-3.json")
@@ -86,7 +106,9 @@ type Index struct {
 type BibJSON struct {
 	Abstract string `json:"abstract"`
 	Author   []struct {
-		Name string `json:"name"`
+		Name        string `json:"name"`
+		Affiliation string `json:"affiliation"`
+		ORCID       string `json:"orcid_id"`
 	} `json:"author"`
 	EndPage    string `json:"end_page"`
 	Identifier []struct {
@@ -132,7 +154,14 @@ func (resp *Response) ToIntermediateSchema() (*finc.IntermediateSchema, error) {
 // Authors returns a list of authors.
 func (doc Document) Authors() (authors []finc.Author) {
 	for _, author := range doc.BibJSON.Author {
-		authors = append(authors, finc.Author{Name: html.UnescapeString(author.Name)})
+		a := finc.Author{
+			Name:  html.UnescapeString(author.Name),
+			ORCID: finc.NormalizeORCID(author.ORCID),
+		}
+		if author.Affiliation != "" {
+			a.Affiliations = []finc.Affiliation{{Name: html.UnescapeString(author.Affiliation)}}
+		}
+		authors = append(authors, a)
 	}
 	return authors
 }
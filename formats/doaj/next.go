@@ -17,7 +17,9 @@ type ArticleV1 struct {
 	Bibjson struct {
 		Abstract string `json:"abstract"`
 		Author   []struct {
-			Name string `json:"name"`
+			Name        string `json:"name"`
+			Affiliation string `json:"affiliation"`
+			ORCID       string `json:"orcid_id"`
 		} `json:"author"`
 		EndPage    string `json:"end_page"`
 		Identifier []struct {
@@ -93,7 +95,14 @@ func (doc ArticleV1) DOI() string {
 // Authors returns a list of authors.
 func (doc ArticleV1) Authors() (authors []finc.Author) {
 	for _, author := range doc.Bibjson.Author {
-		authors = append(authors, finc.Author{Name: html.UnescapeString(author.Name)})
+		a := finc.Author{
+			Name:  html.UnescapeString(author.Name),
+			ORCID: finc.NormalizeORCID(author.ORCID),
+		}
+		if author.Affiliation != "" {
+			a.Affiliations = []finc.Affiliation{{Name: html.UnescapeString(author.Affiliation)}}
+		}
+		authors = append(authors, a)
 	}
 	return authors
 }
@@ -7,7 +7,19 @@
 // }
 package dummy
 
-import "github.com/miku/span/formats/finc"
+import (
+	"github.com/miku/span/formats"
+	"github.com/miku/span/formats/finc"
+)
+
+func init() {
+	formats.Register(formats.Format{
+		Name:        "dummy",
+		Shape:       formats.NDJSON,
+		New:         func() interface{} { return new(Example) },
+		Description: "minimal example format",
+	})
+}
 
 // Example record, which consists only of a title.
 type Example struct {
@@ -1,4 +1,4 @@
-// TODO.
+// Package elsevier converts Elsevier Transport shipments.
 package elsevier
 
 import (
@@ -17,6 +17,7 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	"github.com/kennygrant/sanitize"
+	"github.com/miku/span/formats"
 	"github.com/miku/span/formats/finc"
 )
 
@@ -29,6 +30,18 @@ const (
 	DefaultRefType = "EJOUR"
 )
 
+func init() {
+	formats.Register(formats.Format{
+		Name:         "elsevier-tar",
+		Shape:        formats.Archive,
+		Convert:      convertShipment,
+		ConvertFiles: convertFiles,
+		SourceID:     SourceID,
+		Collection:   Collection,
+		Description:  "Elsevier Transport, shipment tar files or directories",
+	})
+}
+
 var (
 	ErrNoYearFound     = errors.New("no year found")
 	ErrTarFileRequired = errors.New("a tar file is required")
@@ -133,6 +146,13 @@ func (p Pages) Total() string {
 	return strconv.Itoa(t - f)
 }
 
+// IncludeItem references an article from an issue section.
+type IncludeItem struct {
+	Pii   string `xml:"pii"`
+	Doi   string `xml:"doi"`
+	Pages Pages  `xml:"pages"`
+}
+
 // SerialIssue contains information about an issue, usually inside issue.xml.
 type SerialIssue struct {
 	xml.Name  `xml:"serial-issue"`
@@ -161,12 +181,8 @@ type SerialIssue struct {
 			Pages string `xml:"pages"`
 		} `xml:"include-item"`
 		IssueSec []struct {
-			SectionTitle string `xml:"section-title"`
-			IncludeItem  []struct {
-				Pii   string `xml:"pii"`
-				Doi   string `xml:"doi"`
-				Pages Pages  `xml:"pages"`
-			} `xml:"include-item"`
+			SectionTitle string        `xml:"section-title"`
+			IncludeItem  []IncludeItem `xml:"include-item"`
 		} `xml:"issue-sec"`
 	} `xml:"issue-body"`
 }
@@ -360,6 +376,7 @@ func (article Article) Authors() []finc.Author {
 			FirstName: author.GivenName,
 			LastName:  author.Surname,
 			Name:      fmt.Sprintf("%s %s", author.GivenName, author.Surname),
+			ORCID:     finc.NormalizeORCID(author.Orcid),
 		})
 	}
 	if len(authors) == 0 {
@@ -377,7 +394,7 @@ func (article Article) Authors() []finc.Author {
 
 // Shipment is a tar export, looks like SAXC0000000000046A.tar. The tar is not
 // extracted but loaded into memory at once. Issues and articles are stored in a
-// map, each keyed on the PII.
+// map, each keyed on the PII. For large shipments, use a ShipmentReader.
 type Shipment struct {
 	// the full path to the file
 	origin string
@@ -466,8 +483,6 @@ func (s Shipment) BatchConvert() ([]finc.IntermediateSchema, error) {
 		}
 		for _, sec := range si.IssueBody.IssueSec {
 			for _, ii := range sec.IncludeItem {
-				output := finc.NewIntermediateSchema()
-
 				article, ok := s.articles[ii.Pii]
 
 				if !ok {
@@ -475,49 +490,60 @@ func (s Shipment) BatchConvert() ([]finc.IntermediateSchema, error) {
 					continue
 				}
 
-				output.Authors = article.Authors()
-				output.DOI = article.ItemInfo.Doi
-				output.Format = Format
-				output.Genre = Genre
-				output.ISSN = []string{si.IssueInfo.Issn}
-				output.Issue = si.IssueInfo.VolumeIssueNumber.IssFirst
-				output.Languages = []string{"eng"}
-				output.MegaCollections = []string{Collection}
-				output.ID = fmt.Sprintf("ai-%s-%s", SourceID, base64.RawURLEncoding.EncodeToString([]byte(article.ItemInfo.Doi)))
-				output.RecordID = article.ItemInfo.Doi
-				output.RefType = DefaultRefType
-				output.SourceID = SourceID
-				output.Volume = si.IssueInfo.VolumeIssueNumber.VolFirst
-
-				output.ArticleTitle = article.Title()
-				output.JournalTitle = ji.JournalIssueProperties.CollectionTitle
-
-				output.StartPage = ii.Pages.FirstPage
-				output.EndPage = ii.Pages.LastPage
-				output.Pages = ii.Pages.Total()
-
-				output.URL = []string{
-					fmt.Sprintf("http://doi.org/%s", article.ItemInfo.Doi),
-				}
-
-				date, err := article.Date()
+				output, err := convertArticle(ji.JournalIssueProperties.CollectionTitle, si, ii, article)
 				if err != nil {
 					log.Printf("%+v: %s", article.Head, err)
 					continue
 				}
-
-				output.Date = date
-				output.RawDate = date.Format("2006-01-02")
-
-				var buf bytes.Buffer
-				for _, abs := range article.Head.Abstract {
-					buf.WriteString(sanitize.HTML(abs.Text))
-				}
-				output.Abstract = buf.String()
-
 				outputs = append(outputs, *output)
 			}
 		}
 	}
 	return outputs, nil
 }
+
+// convertArticle converts a single article, referenced by an include item in
+// an issue. The journal title is taken from the dataset.
+func convertArticle(journalTitle string, si SerialIssue, ii IncludeItem, article Article) (*finc.IntermediateSchema, error) {
+	output := finc.NewIntermediateSchema()
+
+	output.Authors = article.Authors()
+	output.DOI = article.ItemInfo.Doi
+	output.Format = Format
+	output.Genre = Genre
+	output.ISSN = []string{si.IssueInfo.Issn}
+	output.Issue = si.IssueInfo.VolumeIssueNumber.IssFirst
+	output.Languages = []string{"eng"}
+	output.MegaCollections = []string{Collection}
+	output.ID = fmt.Sprintf("ai-%s-%s", SourceID, base64.RawURLEncoding.EncodeToString([]byte(article.ItemInfo.Doi)))
+	output.RecordID = article.ItemInfo.Doi
+	output.RefType = DefaultRefType
+	output.SourceID = SourceID
+	output.Volume = si.IssueInfo.VolumeIssueNumber.VolFirst
+
+	output.ArticleTitle = article.Title()
+	output.JournalTitle = journalTitle
+
+	output.StartPage = ii.Pages.FirstPage
+	output.EndPage = ii.Pages.LastPage
+	output.Pages = ii.Pages.Total()
+
+	output.URL = []string{
+		fmt.Sprintf("http://doi.org/%s", article.ItemInfo.Doi),
+	}
+
+	date, err := article.Date()
+	if err != nil {
+		return output, err
+	}
+
+	output.Date = date
+	output.RawDate = date.Format("2006-01-02")
+
+	var buf bytes.Buffer
+	for _, abs := range article.Head.Abstract {
+		buf.WriteString(sanitize.HTML(abs.Text))
+	}
+	output.Abstract = buf.String()
+	return output, nil
+}
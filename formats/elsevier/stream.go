@@ -0,0 +1,302 @@
+package elsevier
+
+import (
+	"archive/tar"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/miku/span"
+	"github.com/miku/span/formats"
+	"github.com/miku/span/formats/finc"
+)
+
+// ShipmentReader converts one or more shipments, given as tar files or
+// unpacked directories, without keeping whole shipments in memory. An issue is
+// converted and emitted as soon as its issue.xml, its entry in a dataset.xml
+// and all its articles have been read. Items of a dataset with a delete
+// action are emitted as DELETED skips. Call Close after all input has been
+// read, to emit the remaining, incomplete issues.
+type ShipmentReader struct {
+	emit formats.EmitFunc
+	// titles maps issue PII to journal title, from dataset.xml.
+	titles map[string]string
+	// deleted contains PII of deleted items and issues.
+	deleted map[string]bool
+	// issues, that are not yet complete, keyed by PII.
+	issues map[string]SerialIssue
+	// articles, that are not yet emitted, keyed by PII.
+	articles map[string]Article
+	// issueOf maps article PII to the PII of a pending issue.
+	issueOf map[string]string
+}
+
+// NewShipmentReader creates a new reader, which passes converted records to
+// emit.
+func NewShipmentReader(emit formats.EmitFunc) *ShipmentReader {
+	return &ShipmentReader{
+		emit:     emit,
+		titles:   make(map[string]string),
+		deleted:  make(map[string]bool),
+		issues:   make(map[string]SerialIssue),
+		articles: make(map[string]Article),
+		issueOf:  make(map[string]string),
+	}
+}
+
+// ReadTar reads a shipment tar file.
+func (s *ShipmentReader) ReadTar(r io.Reader) error {
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := s.ReadFile(header.Name, tr); err != nil {
+			return fmt.Errorf("%s: %v", header.Name, err)
+		}
+	}
+}
+
+// ReadDir reads an unpacked shipment. All dataset.xml files are read first,
+// so issues can be emitted early.
+func (s *ShipmentReader) ReadDir(dir string) error {
+	var filenames []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() {
+			filenames = append(filenames, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	isDataset := func(name string) bool { return strings.HasSuffix(name, "dataset.xml") }
+	sort.SliceStable(filenames, func(i, j int) bool {
+		return isDataset(filenames[i]) && !isDataset(filenames[j])
+	})
+	for _, filename := range filenames {
+		f, err := os.Open(filename)
+		if err != nil {
+			return err
+		}
+		err = s.ReadFile(filename, f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %v", filename, err)
+		}
+	}
+	return nil
+}
+
+// ReadFile reads a single file of a shipment, dataset.xml, issue.xml or
+// main.xml. Other files are ignored.
+func (s *ShipmentReader) ReadFile(name string, r io.Reader) error {
+	dec := xml.NewDecoder(r)
+	dec.Strict = false
+	switch {
+	case strings.HasSuffix(name, "main.xml"):
+		var article Article
+		if err := dec.Decode(&article); err != nil {
+			return err
+		}
+		pii := article.ItemInfo.Pii
+		if s.deleted[pii] {
+			return nil
+		}
+		s.articles[pii] = article
+		if issuePii, ok := s.issueOf[pii]; ok {
+			return s.flush(issuePii, false)
+		}
+	case strings.HasSuffix(name, "issue.xml"):
+		var si SerialIssue
+		if err := dec.Decode(&si); err != nil {
+			return err
+		}
+		pii := si.IssueInfo.Pii
+		if s.deleted[pii] {
+			return nil
+		}
+		s.issues[pii] = si
+		for _, sec := range si.IssueBody.IssueSec {
+			for _, ii := range sec.IncludeItem {
+				s.issueOf[ii.Pii] = pii
+			}
+		}
+		return s.flush(pii, false)
+	case strings.HasSuffix(name, "dataset.xml"):
+		var ds Dataset
+		if err := dec.Decode(&ds); err != nil {
+			return err
+		}
+		return s.addDataset(ds)
+	}
+	return nil
+}
+
+// addDataset records journal titles and handles delete actions.
+func (s *ShipmentReader) addDataset(ds Dataset) error {
+	if strings.EqualFold(ds.DatasetProperties.DatasetAction, "delete") {
+		for _, ji := range ds.DatasetContent.JournalIssue {
+			pii := ji.JournalIssueUniqueIds.Pii
+			s.deleted[pii] = true
+			delete(s.issues, pii)
+		}
+		for _, item := range ds.DatasetContent.JournalItem {
+			pii, doi := item.JournalItemUniqueIds.Pii, item.JournalItemUniqueIds.Doi
+			s.deleted[pii] = true
+			delete(s.articles, pii)
+			output := finc.NewIntermediateSchema()
+			output.SourceID = SourceID
+			output.RecordID = doi
+			output.ID = fmt.Sprintf("ai-%s-%s", SourceID, base64.RawURLEncoding.EncodeToString([]byte(doi)))
+			if err := s.emit(output, span.Skip{Reason: fmt.Sprintf("DELETED %s", output.ID)}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, ji := range ds.DatasetContent.JournalIssue {
+		s.titles[ji.JournalIssueUniqueIds.Pii] = ji.JournalIssueProperties.CollectionTitle
+	}
+	for pii := range s.issues {
+		if err := s.flush(pii, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// flush converts and emits the articles of an issue, if the issue is
+// complete. If force is true, available articles are emitted and missing
+// articles are reported as skipped.
+func (s *ShipmentReader) flush(pii string, force bool) error {
+	si, ok := s.issues[pii]
+	if !ok {
+		return nil
+	}
+	title, ok := s.titles[pii]
+	if !ok && !force {
+		return nil
+	}
+	if !force {
+		for _, sec := range si.IssueBody.IssueSec {
+			for _, ii := range sec.IncludeItem {
+				if _, ok := s.articles[ii.Pii]; !ok && !s.deleted[ii.Pii] {
+					return nil
+				}
+			}
+		}
+	}
+	delete(s.issues, pii)
+	for _, sec := range si.IssueBody.IssueSec {
+		for _, ii := range sec.IncludeItem {
+			delete(s.issueOf, ii.Pii)
+		}
+	}
+	if _, ok := s.titles[pii]; !ok {
+		return s.emit(nil, span.Skip{Reason: fmt.Sprintf("ISSUE_NOT_IN_DATASET %s", pii)})
+	}
+	for _, sec := range si.IssueBody.IssueSec {
+		for _, ii := range sec.IncludeItem {
+			if s.deleted[ii.Pii] {
+				continue
+			}
+			article, ok := s.articles[ii.Pii]
+			if !ok {
+				if err := s.emit(nil, span.Skip{Reason: fmt.Sprintf("ARTICLE_NOT_CACHED %s", ii.Pii)}); err != nil {
+					return err
+				}
+				continue
+			}
+			delete(s.articles, ii.Pii)
+			output, err := convertArticle(title, si, ii, article)
+			if err != nil {
+				err = span.Skip{Reason: fmt.Sprintf("NO_DATE %s %v", output.ID, err)}
+			}
+			if err := s.emit(output, err); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Close emits all remaining issues, even if incomplete. Articles without an
+// issue are reported as skipped.
+func (s *ShipmentReader) Close() error {
+	var piis []string
+	for pii := range s.issues {
+		piis = append(piis, pii)
+	}
+	sort.Strings(piis)
+	for _, pii := range piis {
+		if err := s.flush(pii, true); err != nil {
+			return err
+		}
+	}
+	piis = piis[:0]
+	for pii := range s.articles {
+		piis = append(piis, pii)
+	}
+	sort.Strings(piis)
+	for _, pii := range piis {
+		if err := s.emit(nil, span.Skip{Reason: fmt.Sprintf("ISSUE_NOT_CACHED %s", pii)}); err != nil {
+			return err
+		}
+	}
+	s.articles = make(map[string]Article)
+	return nil
+}
+
+// convertShipment converts a single shipment tar file.
+func convertShipment(r io.Reader, emit formats.EmitFunc) error {
+	s := NewShipmentReader(emit)
+	if err := s.ReadTar(r); err != nil {
+		return err
+	}
+	return s.Close()
+}
+
+// convertFiles converts shipments given as tar files, possibly compressed, or
+// directories. All files are read by a single shipment reader, so a shipment
+// may be spread across several files.
+func convertFiles(filenames []string, emit formats.EmitFunc) error {
+	s := NewShipmentReader(emit)
+	for _, filename := range filenames {
+		fi, err := os.Stat(filename)
+		if err != nil {
+			return err
+		}
+		if fi.IsDir() {
+			if err := s.ReadDir(filename); err != nil {
+				return err
+			}
+			continue
+		}
+		f, err := os.Open(filename)
+		if err != nil {
+			return err
+		}
+		r, _, err := span.Decompress(f)
+		if err == nil {
+			err = s.ReadTar(r)
+		}
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %v", filename, err)
+		}
+	}
+	return s.Close()
+}
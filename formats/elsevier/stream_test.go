@@ -0,0 +1,115 @@
+package elsevier
+
+import (
+	"archive/tar"
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/miku/span"
+	"github.com/miku/span/formats/finc"
+)
+
+const testDataset = `<dataset><dataset-properties><dataset-action>%s</dataset-action></dataset-properties>
+<dataset-content>
+<journal-issue><journal-issue-unique-ids><pii>I1</pii></journal-issue-unique-ids>
+<journal-issue-properties><collection-title>Journal One</collection-title></journal-issue-properties></journal-issue>
+<journal-item><journal-item-unique-ids><pii>A3</pii><doi>10.1/a3</doi></journal-item-unique-ids></journal-item>
+</dataset-content></dataset>`
+
+const testIssue = `<serial-issue><issue-info><pii>I1</pii><issn>1234-5678</issn></issue-info>
+<issue-body><issue-sec>
+<include-item><pii>A1</pii><pages><first-page>1</first-page><last-page>5</last-page></pages></include-item>
+<include-item><pii>A2</pii></include-item>
+</issue-sec></issue-body></serial-issue>`
+
+func testArticle(pii, doi string) string {
+	return `<article><item-info><pii>` + pii + `</pii><doi>` + doi + `</doi></item-info>
+<head><title>Title ` + pii + `</title><date-received day="1" month="2" year="2018"/></head></article>`
+}
+
+// tarball creates a tar archive from name, content pairs.
+func tarball(t *testing.T, files ...string) *bytes.Buffer {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for i := 0; i < len(files); i += 2 {
+		body := []byte(files[i+1])
+		if err := tw.WriteHeader(&tar.Header{Name: files[i], Mode: 0644, Size: int64(len(body))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &buf
+}
+
+func TestShipmentReader(t *testing.T) {
+	var (
+		dois    []string
+		skipped []string
+		emitted int // Records emitted before the second tar is read.
+	)
+	s := NewShipmentReader(func(is *finc.IntermediateSchema, err error) error {
+		if err != nil {
+			skipped = append(skipped, span.SkipReason(err.(span.Skip)))
+			return nil
+		}
+		if is.JournalTitle != "Journal One" {
+			t.Errorf("got journal title %q", is.JournalTitle)
+		}
+		dois = append(dois, is.DOI)
+		return nil
+	})
+	// The first tar contains the dataset and a single article.
+	first := tarball(t,
+		"dataset.xml", `<dataset><dataset-content><journal-issue><journal-issue-unique-ids><pii>I1</pii></journal-issue-unique-ids>
+<journal-issue-properties><collection-title>Journal One</collection-title></journal-issue-properties></journal-issue></dataset-content></dataset>`,
+		"1234/I1/A1/main.xml", testArticle("A1", "10.1/a1"),
+		"1234/I1/issue.xml", testIssue,
+		"1234/X/A9/main.xml", testArticle("A9", "10.1/a9"),
+	)
+	if err := s.ReadTar(first); err != nil {
+		t.Fatal(err)
+	}
+	emitted = len(dois)
+	// The missing article of the issue arrives with the second tar.
+	second := tarball(t, "1234/I1/A2/main.xml", testArticle("A2", "10.1/a2"))
+	if err := s.ReadTar(second); err != nil {
+		t.Fatal(err)
+	}
+	if emitted != 0 || !reflect.DeepEqual(dois, []string{"10.1/a1", "10.1/a2"}) {
+		t.Errorf("got %v (%d before completion), want [10.1/a1 10.1/a2] (0)", dois, emitted)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(skipped, []string{"ISSUE_NOT_CACHED"}) {
+		t.Errorf("got %v, want [ISSUE_NOT_CACHED]", skipped)
+	}
+}
+
+func TestShipmentReaderDelete(t *testing.T) {
+	var ids []string
+	s := NewShipmentReader(func(is *finc.IntermediateSchema, err error) error {
+		if skip, ok := err.(span.Skip); !ok || span.SkipReason(skip) != "DELETED" {
+			t.Errorf("got %v, want DELETED skip", err)
+		}
+		ids = append(ids, is.ID)
+		return nil
+	})
+	ds := `<dataset><dataset-properties><dataset-action>Delete</dataset-action></dataset-properties>
+<dataset-content><journal-item><journal-item-unique-ids><pii>A3</pii><doi>10.1/a3</doi></journal-item-unique-ids></journal-item></dataset-content></dataset>`
+	if err := s.ReadTar(tarball(t, "dataset.xml", ds, "1234/I1/A3/main.xml", testArticle("A3", "10.1/a3"))); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ids, []string{"ai-85-MTAuMS9hMw"}) {
+		t.Errorf("got %v, want [ai-85-MTAuMS9hMw]", ids)
+	}
+}
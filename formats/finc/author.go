@@ -0,0 +1,104 @@
+package finc
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Author roles.
+const (
+	RoleAuthor     = "author"
+	RoleEditor     = "editor"
+	RoleTranslator = "translator"
+)
+
+var (
+	// orcidPattern and isniPattern match the bare identifier, optionally
+	// prefixed by the URL of the registry.
+	orcidPattern = regexp.MustCompile(`^(?:(?:https?://)?(?:www\.)?orcid\.org/)?([0-9]{4})-?([0-9]{4})-?([0-9]{4})-?([0-9]{3}[0-9Xx])$`)
+	isniPattern  = regexp.MustCompile(`^(?:(?:https?://)?(?:www\.)?isni\.org/isni/)?([0-9]{4}) ?([0-9]{4}) ?([0-9]{4}) ?([0-9]{3}[0-9Xx])$`)
+	// gndPattern matches GND identifiers for persons, corporate bodies and
+	// other entities, as described in the Wikidata GND property (P227).
+	gndPattern = regexp.MustCompile(`^(1[012]?[0-9]{7}[0-9X]|[47][0-9]{6}-[0-9]|[1-9][0-9]{0,7}-[0-9X]|3[0-9]{7}[0-9X])$`)
+	rorPattern = regexp.MustCompile(`^(?:(?:https?://)?ror\.org/)?(0[a-z0-9]{6}[0-9]{2})$`)
+)
+
+// ParseRole maps contributor types found in various formats to a role. Types
+// like "Author" or "aut" yield an empty role, since author is the default.
+// Unknown types are returned lowercased.
+func ParseRole(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	switch {
+	case s == "", s == "aut", strings.Contains(s, "author"):
+		return ""
+	case s == "edt", strings.HasPrefix(s, "editor"), strings.HasSuffix(s, "editor"):
+		return RoleEditor
+	case s == "trl", strings.HasPrefix(s, "translator"):
+		return RoleTranslator
+	}
+	return s
+}
+
+// RelatorCode returns the MARC relator code for a role, e.g. edt for editor,
+// as used by VuFind in author roles. Unknown roles are returned as is.
+func RelatorCode(role string) string {
+	switch role {
+	case "", RoleAuthor:
+		return "aut"
+	case RoleEditor:
+		return "edt"
+	case RoleTranslator:
+		return "trl"
+	}
+	return role
+}
+
+// IsPrimary returns true, if the author has no role or the author role.
+func (author *Author) IsPrimary() bool {
+	return author.Role == "" || author.Role == RoleAuthor
+}
+
+// NormalizeORCID returns an ORCID iD like 0000-0002-1825-0097 from a bare
+// or URL form, or an empty string, if the value does not look like an ORCID.
+func NormalizeORCID(s string) string {
+	m := orcidPattern.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return ""
+	}
+	return strings.ToUpper(strings.Join(m[1:], "-"))
+}
+
+// NormalizeISNI returns an ISNI as 16 characters without spaces, or an empty
+// string.
+func NormalizeISNI(s string) string {
+	m := isniPattern.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return ""
+	}
+	return strings.ToUpper(strings.Join(m[1:], ""))
+}
+
+// NormalizeGND returns a GND identifier without URL or ISIL prefix, e.g.
+// 118540238 for http://d-nb.info/gnd/118540238 or (DE-588)118540238, or an
+// empty string, if the value is not a valid GND identifier.
+func NormalizeGND(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.LastIndexAny(s, "/)"); i >= 0 {
+		s = s[i+1:]
+	}
+	s = strings.ToUpper(s)
+	if !gndPattern.MatchString(s) {
+		return ""
+	}
+	return s
+}
+
+// NormalizeROR returns a ROR identifier in its canonical URL form, or an
+// empty string.
+func NormalizeROR(s string) string {
+	m := rorPattern.FindStringSubmatch(strings.ToLower(strings.TrimSpace(s)))
+	if m == nil {
+		return ""
+	}
+	return "https://ror.org/" + m[1]
+}
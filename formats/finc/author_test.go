@@ -0,0 +1,101 @@
+package finc
+
+import "testing"
+
+func TestNormalizeGND(t *testing.T) {
+	var tests = []struct {
+		s    string
+		want string
+	}{
+		{"118540238", "118540238"},
+		{"http://d-nb.info/gnd/118540238", "118540238"},
+		{"https://d-nb.info/gnd/4021477-1/", ""},
+		{"https://d-nb.info/gnd/4021477-1", "4021477-1"},
+		{"(DE-588)118540238", "118540238"},
+		{"1012345678", "1012345678"},
+		{"0118540238", ""},
+		{"11854023x", "11854023X"},
+		{"2047474-8", "2047474-8"},
+		{"Volume 12", ""},
+		{"ISBN 978-3-16-148410-0", ""},
+		{"-", ""},
+		{"123-", ""},
+		{"", ""},
+	}
+	for _, c := range tests {
+		if got := NormalizeGND(c.s); got != c.want {
+			t.Errorf("NormalizeGND(%q): got %q, want %q", c.s, got, c.want)
+		}
+	}
+}
+
+func TestNormalizeORCID(t *testing.T) {
+	var tests = []struct {
+		s    string
+		want string
+	}{
+		{"0000-0002-1825-0097", "0000-0002-1825-0097"},
+		{"0000000218250097", "0000-0002-1825-0097"},
+		{"https://orcid.org/0000-0002-1825-0097", "0000-0002-1825-0097"},
+		{"http://www.orcid.org/0000-0002-1825-0097", "0000-0002-1825-0097"},
+		{" orcid.org/0000-0002-1825-0097 ", "0000-0002-1825-0097"},
+		{"0000-0002-9079-593x", "0000-0002-9079-593X"},
+		{"https://orcid.org/0000-0002-9079-593x", "0000-0002-9079-593X"},
+		{"Volume 1234567890123456", ""},
+		{"https://example.com/0000-0002-1825-0097", ""},
+		{"10000-0002-1825-0097", ""},
+		{"0000-0002-1825", ""},
+		{"", ""},
+	}
+	for _, c := range tests {
+		if got := NormalizeORCID(c.s); got != c.want {
+			t.Errorf("NormalizeORCID(%q): got %q, want %q", c.s, got, c.want)
+		}
+	}
+}
+
+func TestNormalizeISNI(t *testing.T) {
+	var tests = []struct {
+		s    string
+		want string
+	}{
+		{"0000000121464364", "0000000121464364"},
+		{"0000 0001 2146 4364", "0000000121464364"},
+		{"https://isni.org/isni/0000000121464364", "0000000121464364"},
+		{"http://www.isni.org/isni/0000000121464364", "0000000121464364"},
+		{"isni.org/isni/000000012146438x", "000000012146438X"},
+		{"0000 0001 2146 438x", "000000012146438X"},
+		{"ISBN 1234567890123456", ""},
+		{"https://example.com/isni/0000000121464364", ""},
+		{"10000000121464364", ""},
+		{"000000012146", ""},
+		{"", ""},
+	}
+	for _, c := range tests {
+		if got := NormalizeISNI(c.s); got != c.want {
+			t.Errorf("NormalizeISNI(%q): got %q, want %q", c.s, got, c.want)
+		}
+	}
+}
+
+func TestNormalizeROR(t *testing.T) {
+	var tests = []struct {
+		s    string
+		want string
+	}{
+		{"03yrm5c26", "https://ror.org/03yrm5c26"},
+		{"https://ror.org/03yrm5c26", "https://ror.org/03yrm5c26"},
+		{"HTTPS://ROR.ORG/03YRM5C26", "https://ror.org/03yrm5c26"},
+		{"ror.org/03yrm5c26 ", "https://ror.org/03yrm5c26"},
+		{"https://example.com/03yrm5c26", ""},
+		{"Room 03yrm5c26", ""},
+		{"13yrm5c26", ""},
+		{"03yrm5c2x", ""},
+		{"", ""},
+	}
+	for _, c := range tests {
+		if got := NormalizeROR(c.s); got != c.want {
+			t.Errorf("NormalizeROR(%q): got %q, want %q", c.s, got, c.want)
+		}
+	}
+}
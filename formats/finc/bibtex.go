@@ -0,0 +1,157 @@
+package finc
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// BibTeX exports records as BibTeX entries, keyed by finc id.
+type BibTeX struct{}
+
+// bibtexKindTypes maps kinds of works to BibTeX entry types. There are no
+// standard or dataset types in classic BibTeX.
+var bibtexKindTypes = map[string]string{
+	kindArticle:    "article",
+	kindChapter:    "incollection",
+	kindBook:       "book",
+	kindConference: "inproceedings",
+	kindReport:     "techreport",
+	kindThesis:     "phdthesis",
+	kindStandard:   "misc",
+	kindDataset:    "misc",
+	kindGeneric:    "misc",
+}
+
+// bibtexEscaper escapes characters with a special meaning in BibTeX values.
+var bibtexEscaper = strings.NewReplacer(
+	`\`, `\textbackslash{}`,
+	"{", `\{`,
+	"}", `\}`,
+	"&", `\&`,
+	"%", `\%`,
+	"$", `\$`,
+	"#", `\#`,
+	"_", `\_`,
+	"~", `\textasciitilde{}`,
+	"^", `\textasciicircum{}`,
+)
+
+// bibtexKeyCleaner removes characters not allowed in a citation key.
+var bibtexKeyCleaner = strings.NewReplacer(",", "", "{", "", "}", "", "%", "", "#", "", " ", "", `\`, "")
+
+// bibtexName formats an author as "Last, Suffix, First", corporate names are
+// braced, so they are not split.
+func bibtexName(author Author) string {
+	switch {
+	case author.LastName != "":
+		parts := []string{author.LastName}
+		if author.Suffix != "" {
+			parts = append(parts, author.Suffix)
+		}
+		if author.FirstName != "" {
+			parts = append(parts, author.FirstName)
+		}
+		return bibtexEscaper.Replace(strings.Join(parts, ", "))
+	case author.Corporation != "":
+		return "{" + bibtexEscaper.Replace(author.Corporation) + "}"
+	}
+	return bibtexEscaper.Replace(author.String())
+}
+
+// bibtexNames joins names with "and".
+func bibtexNames(authors []Author) string {
+	var names []string
+	for _, author := range authors {
+		if name := bibtexName(author); name != "" {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, " and ")
+}
+
+// Type returns the BibTeX entry type of a record.
+func (s *BibTeX) Type(is IntermediateSchema) string {
+	return bibtexKindTypes[citationKind(is)]
+}
+
+// Export returns a single BibTeX entry.
+func (s *BibTeX) Export(is IntermediateSchema, _ bool) ([]byte, error) {
+	var buf bytes.Buffer
+	key := bibtexKeyCleaner.Replace(is.ID)
+	if key == "" {
+		return nil, fmt.Errorf("bibtex: record without id")
+	}
+	fmt.Fprintf(&buf, "@%s{%s", s.Type(is), key)
+
+	// field writes a field, if the value is not empty, raw values are not
+	// escaped, e.g. URL and DOI.
+	field := func(name, value string, raw bool) {
+		value = strings.Join(strings.Fields(value), " ")
+		if value == "" {
+			return
+		}
+		if !raw {
+			value = bibtexEscaper.Replace(value)
+		}
+		fmt.Fprintf(&buf, ",\n  %s = {%s}", name, value)
+	}
+
+	field("title", citationTitle(is), false)
+	if names := bibtexNames(authorsByRole(is, "")); names != "" {
+		fmt.Fprintf(&buf, ",\n  author = {%s}", names)
+	}
+	if names := bibtexNames(authorsByRole(is, RoleEditor)); names != "" {
+		fmt.Fprintf(&buf, ",\n  editor = {%s}", names)
+	}
+	switch ct := containerTitle(is); citationKind(is) {
+	case kindArticle:
+		field("journal", ct, false)
+	case kindChapter, kindConference:
+		field("booktitle", ct, false)
+	default:
+		field("howpublished", ct, false)
+	}
+	if is.Event != nil {
+		field("eventtitle", is.Event.Name, false)
+		field("venue", is.Event.Place, false)
+	}
+	field("series", is.Series, false)
+	if year, month, _ := dateParts(is); year != "" {
+		field("year", year, true)
+		if m, err := time.Parse("01", month); err == nil {
+			// Month macros, like jan, are not braced.
+			fmt.Fprintf(&buf, ",\n  month = %s", strings.ToLower(m.Format("Jan")))
+		}
+	}
+	field("volume", is.Volume, false)
+	field("number", is.Issue, false)
+	switch start, end := pageRange(is); {
+	case start != "" && end != "" && start != end:
+		field("pages", start+"--"+end, false)
+	case start != "":
+		field("pages", start, false)
+	}
+	field("edition", is.Edition, false)
+	switch citationKind(is) {
+	case kindReport:
+		field("institution", strings.Join(is.Publishers, " and "), false)
+	case kindThesis:
+		field("school", strings.Join(is.Publishers, " and "), false)
+	default:
+		field("publisher", strings.Join(is.Publishers, " and "), false)
+	}
+	field("address", strings.Join(is.Places, " and "), false)
+	field("issn", strings.Join(identifiers(is.ISSN, is.EISSN), ", "), false)
+	field("isbn", strings.Join(identifiers(is.ISBN, is.EISBN), ", "), false)
+	field("doi", is.DOI, true)
+	if len(is.URL) > 0 {
+		field("url", is.URL[0], true)
+	}
+	field("abstract", is.Abstract, false)
+	field("keywords", strings.Join(is.Subjects, ", "), false)
+	field("language", strings.Join(is.Languages, ", "), false)
+	buf.WriteString("\n}\n")
+	return buf.Bytes(), nil
+}
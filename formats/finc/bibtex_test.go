@@ -0,0 +1,84 @@
+package finc
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBibTeXExport(t *testing.T) {
+	is := IntermediateSchema{
+		ID:           "ai-1-x",
+		Genre:        "article",
+		ArticleTitle: `Costs & Benefits of 100% R_D {x} at $5 #1 ~ ^ \`,
+		JournalTitle: "J. Econ.",
+		Authors: []Author{
+			{LastName: "Doe", FirstName: "Jane"},
+			{Corporation: "Smith & Sons"},
+			{LastName: "King", FirstName: "Martin", Suffix: "Jr."},
+			{LastName: "Roe", Role: RoleEditor},
+		},
+		RawDate:    "2019-02-03T10:00:00Z",
+		StartPage:  "10",
+		EndPage:    "20",
+		Publishers: []string{"A & B"},
+		DOI:        "10.1/a_b",
+		URL:        []string{"http://x.org/a_b", "http://x.org/c"},
+	}
+	want := `@article{ai-1-x,
+  title = {Costs \& Benefits of 100\% R\_D \{x\} at \$5 \#1 \textasciitilde{} \textasciicircum{} \textbackslash{}},
+  author = {Doe, Jane and {Smith \& Sons} and King, Jr., Martin},
+  editor = {Roe},
+  journal = {J. Econ.},
+  year = {2019},
+  month = feb,
+  pages = {10--20},
+  publisher = {A \& B},
+  doi = {10.1/a_b},
+  url = {http://x.org/a_b}
+}
+`
+	b, err := new(BibTeX).Export(is, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", b, want)
+	}
+}
+
+func TestBibTeXMonth(t *testing.T) {
+	var tests = []struct {
+		raw  string
+		want string
+	}{
+		{"2019-01-01", "month = jan"},
+		{"2019-12", "month = dec"},
+		{"2019", ""},
+		{"2019-13", ""},
+	}
+	for _, c := range tests {
+		b, err := new(BibTeX).Export(IntermediateSchema{ID: "ai-1-x", RawDate: c.raw}, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(b), "year = {2019}") {
+			t.Errorf("%q: year missing in %s", c.raw, b)
+		}
+		if got := strings.Contains(string(b), "month"); got != (c.want != "") || !strings.Contains(string(b), c.want) {
+			t.Errorf("%q: got %s, want %q", c.raw, b, c.want)
+		}
+	}
+}
+
+func TestBibTeXKey(t *testing.T) {
+	b, err := new(BibTeX).Export(IntermediateSchema{ID: "ai-1-a b,{c}"}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "@misc{ai-1-abc\n}\n"; string(b) != want {
+		t.Errorf("got %q, want %q", b, want)
+	}
+	if _, err := new(BibTeX).Export(IntermediateSchema{ID: ", "}, false); err == nil {
+		t.Errorf("expected error for record without usable id")
+	}
+}
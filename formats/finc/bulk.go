@@ -0,0 +1,47 @@
+package finc
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Bulk exports records in the newline delimited bulk format of Elasticsearch
+// and OpenSearch: an index action, followed by the document. Documents are
+// built with a mapping, the document id is taken from an intermediate schema
+// field, e.g. finc.id.
+type Bulk struct {
+	Index   string
+	IDField string
+	Mapping *Mapping
+}
+
+// bulkAction is the action line of a single document.
+type bulkAction struct {
+	Index struct {
+		Index string `json:"_index,omitempty"`
+		ID    string `json:"_id"`
+	} `json:"index"`
+}
+
+// Export returns an action and a document line, without a trailing newline.
+func (b *Bulk) Export(is IntermediateSchema, withFullrecord bool) ([]byte, error) {
+	values, err := is.Field(b.IDField)
+	if err != nil {
+		return nil, err
+	}
+	if len(values) == 0 || values[0] == "" {
+		return nil, fmt.Errorf("bulk: record without %s", b.IDField)
+	}
+	var action bulkAction
+	action.Index.Index = b.Index
+	action.Index.ID = values[0]
+	line, err := json.Marshal(action)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := b.Mapping.Export(is, withFullrecord)
+	if err != nil {
+		return nil, err
+	}
+	return append(append(line, '\n'), doc...), nil
+}
@@ -0,0 +1,195 @@
+package finc
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/miku/span/container"
+)
+
+// Kinds of works, which the citation exporters map to their own types.
+const (
+	kindArticle    = "article"
+	kindChapter    = "chapter"
+	kindBook       = "book"
+	kindConference = "conference"
+	kindReport     = "report"
+	kindThesis     = "thesis"
+	kindStandard   = "standard"
+	kindDataset    = "dataset"
+	kindGeneric    = "generic"
+)
+
+// risTypes are the valid RIS reference types.
+var risTypes = container.NewStringSet("ABST", "ADVS", "AGGR", "ANCIENT", "ART",
+	"BILL", "BLOG", "BOOK", "CASE", "CHAP", "CHART", "CLSWK", "COMP", "CONF",
+	"CPAPER", "CTLG", "DATA", "DBASE", "DICT", "EBOOK", "ECHAP", "EDBOOK",
+	"EJOUR", "ELEC", "ENCYC", "EQUA", "FIGURE", "GEN", "GOVDOC", "GRANT",
+	"HEAR", "ICOMM", "INPR", "JFULL", "JOUR", "LEGAL", "MANSCPT", "MAP", "MGZN",
+	"MPCT", "MULTI", "MUSIC", "NEWS", "PAMP", "PAT", "PCOMM", "RPRT", "SER",
+	"SLIDE", "SOUND", "STAND", "STAT", "THES", "UNBILL", "UNPB", "VIDEO")
+
+// citationKind guesses the kind of work from reference type, genre and
+// format, in that order.
+func citationKind(is IntermediateSchema) string {
+	switch is.RefType {
+	case "JOUR", "EJOUR", "MGZN", "NEWS", "INPR":
+		return kindArticle
+	case "CHAP", "ECHAP":
+		return kindChapter
+	case "BOOK", "EBOOK", "EDBOOK":
+		return kindBook
+	case "CPAPER", "CONF":
+		return kindConference
+	case "RPRT":
+		return kindReport
+	case "THES":
+		return kindThesis
+	case "STAND":
+		return kindStandard
+	case "DATA":
+		return kindDataset
+	}
+	switch is.Genre {
+	case "article", "issue", "journal":
+		return kindArticle
+	case "bookitem":
+		return kindChapter
+	case "book":
+		return kindBook
+	case "proceeding", "conference":
+		return kindConference
+	case "report":
+		return kindReport
+	}
+	switch {
+	case strings.Contains(is.Format, "Thesis"):
+		return kindThesis
+	case strings.Contains(is.Format, "BookPart"):
+		return kindChapter
+	case strings.Contains(is.Format, "Book"):
+		return kindBook
+	case strings.Contains(is.Format, "Article"):
+		return kindArticle
+	}
+	return kindGeneric
+}
+
+// citationTitle returns the title of the work, with subtitle.
+func citationTitle(is IntermediateSchema) string {
+	title := is.ArticleTitle
+	if title == "" || (citationKind(is) == kindBook && is.BookTitle != "") {
+		title = is.BookTitle
+	}
+	if is.ArticleSubtitle != "" && !strings.Contains(title, is.ArticleSubtitle) {
+		title = title + ": " + is.ArticleSubtitle
+	}
+	return strings.TrimSpace(title)
+}
+
+// containerTitle returns the title of the journal, book or proceedings the
+// work appeared in, if any.
+func containerTitle(is IntermediateSchema) string {
+	switch citationKind(is) {
+	case kindBook, kindThesis, kindReport:
+		return ""
+	case kindChapter, kindConference:
+		if is.BookTitle != "" && is.BookTitle != is.ArticleTitle {
+			return is.BookTitle
+		}
+	}
+	if is.JournalTitle == is.ArticleTitle {
+		return ""
+	}
+	return is.JournalTitle
+}
+
+// pageRange returns start and end page, ignoring zero values, that some
+// sources use for missing pages.
+func pageRange(is IntermediateSchema) (start, end string) {
+	clean := func(s string) string {
+		s = strings.TrimSpace(s)
+		if strings.Trim(s, "0") == "" {
+			return ""
+		}
+		return s
+	}
+	start, end = clean(is.StartPage), clean(is.EndPage)
+	if start == "" && end == "" && is.Pages != "" {
+		parts := strings.SplitN(is.Pages, "-", 2)
+		start = clean(parts[0])
+		if len(parts) == 2 {
+			end = clean(parts[1])
+		}
+	}
+	return start, end
+}
+
+// dateParts returns year, month and day of the record date, as found in
+// RawDate, month and day may be empty. A time suffix, like T10:00:00Z, is
+// ignored, month and day are zero padded.
+func dateParts(is IntermediateSchema) (year, month, day string) {
+	raw := strings.TrimSpace(is.RawDate)
+	if i := strings.IndexAny(raw, "T "); i >= 0 {
+		raw = raw[:i]
+	}
+	parts := strings.SplitN(raw, "-", 3)
+	if _, err := strconv.Atoi(parts[0]); err != nil || len(parts[0]) != 4 {
+		if is.Date.IsZero() {
+			return "", "", ""
+		}
+		return is.Date.Format("2006"), is.Date.Format("01"), is.Date.Format("02")
+	}
+	// number returns a zero padded number in [1, max] or an empty string.
+	number := func(s string, max int) string {
+		v, err := strconv.Atoi(s)
+		if err != nil || v < 1 || v > max {
+			return ""
+		}
+		return fmt.Sprintf("%02d", v)
+	}
+	year = parts[0]
+	if len(parts) > 1 {
+		month = number(parts[1], 12)
+	}
+	if len(parts) > 2 && month != "" {
+		day = number(parts[2], 31)
+	}
+	return year, month, day
+}
+
+// citationName returns the name of an author, or the corporation for
+// corporate authors.
+func citationName(author Author) string {
+	if author.Name == "" && author.LastName == "" && author.Corporation != "" {
+		return author.Corporation
+	}
+	return author.String()
+}
+
+// authorsByRole returns the authors with a given role, primary authors for
+// an empty role.
+func authorsByRole(is IntermediateSchema, role string) (result []Author) {
+	for _, author := range is.Authors {
+		if (role == "" && author.IsPrimary()) || (role != "" && author.Role == role) {
+			result = append(result, author)
+		}
+	}
+	return result
+}
+
+// identifiers returns ISSN and EISSN or ISBN and EISBN in order, without
+// duplicates.
+func identifiers(lists ...[]string) (result []string) {
+	seen := make(map[string]bool)
+	for _, list := range lists {
+		for _, v := range list {
+			if v = strings.TrimSpace(v); v != "" && !seen[v] {
+				seen[v] = true
+				result = append(result, v)
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,85 @@
+package finc
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestDateParts(t *testing.T) {
+	var tests = []struct {
+		raw              string
+		date             time.Time
+		year, month, day string
+	}{
+		{"2019-02-03", time.Time{}, "2019", "02", "03"},
+		{"2019-02-03T10:00:00Z", time.Time{}, "2019", "02", "03"},
+		{"2019-02-03 10:00:00", time.Time{}, "2019", "02", "03"},
+		{"2019-2-3", time.Time{}, "2019", "02", "03"},
+		{"2019-02", time.Time{}, "2019", "02", ""},
+		{"2019", time.Time{}, "2019", "", ""},
+		{"2019-00-00", time.Time{}, "2019", "", ""},
+		{"2019-13-01", time.Time{}, "2019", "", ""},
+		{"2019-xx-01", time.Time{}, "2019", "", ""},
+		{"", time.Time{}, "", "", ""},
+		{"19", time.Date(2019, 4, 5, 0, 0, 0, 0, time.UTC), "2019", "04", "05"},
+	}
+	for _, c := range tests {
+		year, month, day := dateParts(IntermediateSchema{RawDate: c.raw, Date: c.date})
+		if year != c.year || month != c.month || day != c.day {
+			t.Errorf("dateParts(%q): got %q %q %q, want %q %q %q",
+				c.raw, year, month, day, c.year, c.month, c.day)
+		}
+	}
+}
+
+func TestCitationKinds(t *testing.T) {
+	var tests = []struct {
+		is     IntermediateSchema
+		kind   string
+		ris    string
+		bibtex string
+		csl    string
+	}{
+		{IntermediateSchema{RefType: "JOUR"}, kindArticle, "JOUR", "article", "article-journal"},
+		{IntermediateSchema{Genre: "article"}, kindArticle, "JOUR", "article", "article-journal"},
+		{IntermediateSchema{Format: "ElectronicArticle"}, kindArticle, "JOUR", "article", "article-journal"},
+		{IntermediateSchema{Genre: "bookitem"}, kindChapter, "CHAP", "incollection", "chapter"},
+		{IntermediateSchema{Format: "ElectronicBookPart"}, kindChapter, "CHAP", "incollection", "chapter"},
+		{IntermediateSchema{RefType: "EBOOK"}, kindBook, "EBOOK", "book", "book"},
+		{IntermediateSchema{Format: "Book"}, kindBook, "BOOK", "book", "book"},
+		{IntermediateSchema{Genre: "proceeding"}, kindConference, "CPAPER", "inproceedings", "paper-conference"},
+		{IntermediateSchema{RefType: "RPRT"}, kindReport, "RPRT", "techreport", "report"},
+		{IntermediateSchema{Format: "ElectronicThesis"}, kindThesis, "THES", "phdthesis", "thesis"},
+		{IntermediateSchema{RefType: "STAND"}, kindStandard, "STAND", "misc", "standard"},
+		{IntermediateSchema{RefType: "DATA"}, kindDataset, "DATA", "misc", "dataset"},
+		{IntermediateSchema{}, kindGeneric, "GEN", "misc", "document"},
+		// Valid RIS types are kept, even if they map to no specific kind.
+		{IntermediateSchema{RefType: "MAP"}, kindGeneric, "MAP", "misc", "document"},
+		// Reference type takes precedence over genre.
+		{IntermediateSchema{RefType: "THES", Genre: "article"}, kindThesis, "THES", "phdthesis", "thesis"},
+	}
+	for _, c := range tests {
+		c.is.ID = "ai-1-x"
+		if kind := citationKind(c.is); kind != c.kind {
+			t.Errorf("%+v: kind got %s, want %s", c.is, kind, c.kind)
+		}
+		if typ := new(RIS).Type(c.is); typ != c.ris {
+			t.Errorf("%+v: RIS type got %s, want %s", c.is, typ, c.ris)
+		}
+		if typ := new(BibTeX).Type(c.is); typ != c.bibtex {
+			t.Errorf("%+v: BibTeX type got %s, want %s", c.is, typ, c.bibtex)
+		}
+		b, err := new(CSLJSON).Export(c.is, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var item CSLJSON
+		if err := json.Unmarshal(b, &item); err != nil {
+			t.Fatal(err)
+		}
+		if item.Type != c.csl {
+			t.Errorf("%+v: CSL type got %s, want %s", c.is, item.Type, c.csl)
+		}
+	}
+}
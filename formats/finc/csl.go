@@ -0,0 +1,150 @@
+package finc
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// CSLJSON exports records as CSL-JSON items, as used by citeproc processors
+// and Zotero. Items are written one per line, not as a single array.
+type CSLJSON struct {
+	ID             string    `json:"id"`
+	Type           string    `json:"type"`
+	Title          string    `json:"title,omitempty"`
+	ContainerTitle string    `json:"container-title,omitempty"`
+	ShortContainer string    `json:"container-title-short,omitempty"`
+	CollectionName string    `json:"collection-title,omitempty"`
+	Author         []CSLName `json:"author,omitempty"`
+	Editor         []CSLName `json:"editor,omitempty"`
+	Translator     []CSLName `json:"translator,omitempty"`
+	Issued         *CSLDate  `json:"issued,omitempty"`
+	Volume         string    `json:"volume,omitempty"`
+	Issue          string    `json:"issue,omitempty"`
+	Page           string    `json:"page,omitempty"`
+	PageFirst      string    `json:"page-first,omitempty"`
+	NumberOfPages  string    `json:"number-of-pages,omitempty"`
+	Number         string    `json:"number,omitempty"`
+	Edition        string    `json:"edition,omitempty"`
+	Publisher      string    `json:"publisher,omitempty"`
+	PublisherPlace string    `json:"publisher-place,omitempty"`
+	EventTitle     string    `json:"event-title,omitempty"`
+	EventPlace     string    `json:"event-place,omitempty"`
+	ISSN           string    `json:"ISSN,omitempty"`
+	ISBN           string    `json:"ISBN,omitempty"`
+	DOI            string    `json:"DOI,omitempty"`
+	URL            string    `json:"URL,omitempty"`
+	Abstract       string    `json:"abstract,omitempty"`
+	Keyword        string    `json:"keyword,omitempty"`
+	Language       string    `json:"language,omitempty"`
+	Source         string    `json:"source,omitempty"`
+}
+
+// CSLName is a structured or literal name.
+type CSLName struct {
+	Family  string `json:"family,omitempty"`
+	Given   string `json:"given,omitempty"`
+	Suffix  string `json:"suffix,omitempty"`
+	Literal string `json:"literal,omitempty"`
+}
+
+// CSLDate is a date with year, month and day parts, as available.
+type CSLDate struct {
+	DateParts [][]int `json:"date-parts"`
+}
+
+// cslKindTypes maps kinds of works to CSL item types.
+var cslKindTypes = map[string]string{
+	kindArticle:    "article-journal",
+	kindChapter:    "chapter",
+	kindBook:       "book",
+	kindConference: "paper-conference",
+	kindReport:     "report",
+	kindThesis:     "thesis",
+	kindStandard:   "standard",
+	kindDataset:    "dataset",
+	kindGeneric:    "document",
+}
+
+// cslNames converts authors to CSL names.
+func cslNames(authors []Author) (names []CSLName) {
+	for _, author := range authors {
+		switch {
+		case author.LastName != "":
+			names = append(names, CSLName{
+				Family: author.LastName,
+				Given:  author.FirstName,
+				Suffix: author.Suffix,
+			})
+		case author.Corporation != "":
+			names = append(names, CSLName{Literal: author.Corporation})
+		case author.String() != "":
+			names = append(names, CSLName{Literal: author.String()})
+		}
+	}
+	return names
+}
+
+// cslDate returns the record date with the available precision or nil.
+func cslDate(is IntermediateSchema) *CSLDate {
+	year, month, day := dateParts(is)
+	var parts []int
+	for _, s := range []string{year, month, day} {
+		v, err := strconv.Atoi(s)
+		if err != nil || v == 0 {
+			break
+		}
+		parts = append(parts, v)
+	}
+	if len(parts) == 0 {
+		return nil
+	}
+	return &CSLDate{DateParts: [][]int{parts}}
+}
+
+// Export returns a single CSL-JSON item.
+func (s *CSLJSON) Export(is IntermediateSchema, _ bool) ([]byte, error) {
+	*s = CSLJSON{
+		ID:             is.ID,
+		Type:           cslKindTypes[citationKind(is)],
+		Title:          citationTitle(is),
+		ContainerTitle: containerTitle(is),
+		ShortContainer: is.ShortTitle,
+		CollectionName: is.Series,
+		Author:         cslNames(authorsByRole(is, "")),
+		Editor:         cslNames(authorsByRole(is, RoleEditor)),
+		Translator:     cslNames(authorsByRole(is, RoleTranslator)),
+		Issued:         cslDate(is),
+		Volume:         is.Volume,
+		Issue:          is.Issue,
+		Number:         is.ArticleNumber,
+		Edition:        is.Edition,
+		Publisher:      strings.Join(is.Publishers, "; "),
+		PublisherPlace: strings.Join(is.Places, "; "),
+		ISSN:           strings.Join(identifiers(is.ISSN, is.EISSN), ", "),
+		ISBN:           strings.Join(identifiers(is.ISBN, is.EISBN), ", "),
+		DOI:            is.DOI,
+		Abstract:       is.Abstract,
+		Keyword:        strings.Join(is.Subjects, ", "),
+		Source:         is.DataProvider,
+	}
+	switch start, end := pageRange(is); {
+	case start != "" && end != "" && start != end:
+		s.Page, s.PageFirst = start+"-"+end, start
+	case start != "":
+		s.Page, s.PageFirst = start, start
+	}
+	if citationKind(is) == kindBook {
+		s.NumberOfPages = is.PageCount
+	}
+	if is.Event != nil {
+		s.EventTitle, s.EventPlace = is.Event.Name, is.Event.Place
+	}
+	if len(is.URL) > 0 {
+		s.URL = is.URL[0]
+	}
+	if len(is.Languages) > 0 {
+		s.Language = is.Languages[0]
+	}
+	return json.Marshal(s)
+}
@@ -0,0 +1,70 @@
+package finc
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestCSLExport(t *testing.T) {
+	is := IntermediateSchema{
+		ID:           "ai-1-x",
+		RefType:      "CHAP",
+		ArticleTitle: "A Chapter",
+		BookTitle:    "A Book",
+		Authors: []Author{
+			{LastName: "Doe", FirstName: "Jane", Suffix: "Jr."},
+			{Corporation: "ACME Corp."},
+			{Name: "Rick Roe", Role: RoleEditor},
+		},
+		RawDate:    "2019-02-03T10:00:00Z",
+		StartPage:  "10",
+		EndPage:    "20",
+		ISBN:       []string{"9780804429573"},
+		Publishers: []string{"A", "B"},
+		Languages:  []string{"eng", "ger"},
+	}
+	want := CSLJSON{
+		ID:             "ai-1-x",
+		Type:           "chapter",
+		Title:          "A Chapter",
+		ContainerTitle: "A Book",
+		Author:         []CSLName{{Family: "Doe", Given: "Jane", Suffix: "Jr."}, {Literal: "ACME Corp."}},
+		Editor:         []CSLName{{Literal: "Rick Roe"}},
+		Issued:         &CSLDate{DateParts: [][]int{{2019, 2, 3}}},
+		Page:           "10-20",
+		PageFirst:      "10",
+		Publisher:      "A; B",
+		ISBN:           "9780804429573",
+		Language:       "eng",
+	}
+	b, err := new(CSLJSON).Export(is, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got CSLJSON
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestCSLDate(t *testing.T) {
+	var tests = []struct {
+		raw  string
+		want *CSLDate
+	}{
+		{"2019-02-03", &CSLDate{DateParts: [][]int{{2019, 2, 3}}}},
+		{"2019-02", &CSLDate{DateParts: [][]int{{2019, 2}}}},
+		{"2019", &CSLDate{DateParts: [][]int{{2019}}}},
+		{"2019-00-03", &CSLDate{DateParts: [][]int{{2019}}}},
+		{"", nil},
+	}
+	for _, c := range tests {
+		if got := cslDate(IntermediateSchema{RawDate: c.raw}); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%q: got %+v, want %+v", c.raw, got, c.want)
+		}
+	}
+}
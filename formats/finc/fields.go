@@ -0,0 +1,107 @@
+package finc
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// fieldIndex maps the JSON key of a string, string slice or bool field of an
+// intermediate schema to its struct field index, e.g. "rft.atitle" to the
+// index of ArticleTitle.
+var fieldIndex = make(map[string]int)
+
+func init() {
+	t := reflect.TypeOf(IntermediateSchema{})
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		key := strings.Split(f.Tag.Get("json"), ",")[0]
+		if key == "" || key == "-" {
+			continue
+		}
+		switch {
+		case f.Type.Kind() == reflect.String:
+		case f.Type.Kind() == reflect.Bool:
+		case f.Type.Kind() == reflect.Slice && f.Type.Elem().Kind() == reflect.String:
+		default:
+			continue
+		}
+		fieldIndex[key] = i
+	}
+}
+
+// SetField sets the string, string slice or bool field given by its JSON key,
+// e.g. "rft.atitle" or "rft.issn". Values are appended to slices. A string
+// field receives the first non-empty value, a bool field its boolean value.
+// Unknown keys and fields of other types result in an error.
+func (is *IntermediateSchema) SetField(key string, values ...string) error {
+	i, ok := fieldIndex[key]
+	if !ok {
+		return fmt.Errorf("cannot set field: %s", key)
+	}
+	v := reflect.ValueOf(is).Elem().Field(i)
+	switch v.Kind() {
+	case reflect.String:
+		for _, s := range values {
+			if s != "" {
+				v.SetString(s)
+				break
+			}
+		}
+	case reflect.Slice:
+		for _, s := range values {
+			if s != "" {
+				v.Set(reflect.Append(v, reflect.ValueOf(s)))
+			}
+		}
+	case reflect.Bool:
+		for _, s := range values {
+			if s != "" {
+				b, err := strconv.ParseBool(s)
+				if err != nil {
+					return fmt.Errorf("cannot set field %s: %v", key, err)
+				}
+				v.SetBool(b)
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// Field returns the values of a string, string slice or bool field given by
+// its JSON key. An empty string or false bool field yields no values, as
+// these are omitted in JSON, too.
+func (is *IntermediateSchema) Field(key string) ([]string, error) {
+	i, ok := fieldIndex[key]
+	if !ok {
+		return nil, fmt.Errorf("unknown field: %s", key)
+	}
+	v := reflect.ValueOf(is).Elem().Field(i)
+	switch v.Kind() {
+	case reflect.String:
+		if v.String() == "" {
+			return nil, nil
+		}
+		return []string{v.String()}, nil
+	case reflect.Bool:
+		if !v.Bool() {
+			return nil, nil
+		}
+		return []string{"true"}, nil
+	default:
+		return append([]string(nil), v.Interface().([]string)...), nil
+	}
+}
+
+// ClearField resets a string, string slice or bool field given by its JSON key.
+func (is *IntermediateSchema) ClearField(key string) error {
+	i, ok := fieldIndex[key]
+	if !ok {
+		return fmt.Errorf("cannot clear field: %s", key)
+	}
+	v := reflect.ValueOf(is).Elem().Field(i)
+	v.Set(reflect.Zero(v.Type()))
+	return nil
+}
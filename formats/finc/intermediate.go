@@ -48,6 +48,12 @@ type Exporter interface {
 	Export(is IntermediateSchema, withFullrecord bool) ([]byte, error)
 }
 
+// SelfDelimiter is implemented by exporters, whose records carry their own
+// terminator, e.g. binary MARC. Such records are not separated by newlines.
+type SelfDelimiter interface {
+	SelfDelimiting() bool
+}
+
 // Author representes an author, "inspired" by OpenURL.
 type Author struct {
 	ID           string `json:"x.id,omitempty"`
@@ -59,6 +65,22 @@ type Author struct {
 	MiddleName   string `json:"rft.auinitm,omitempty"`
 	Suffix       string `json:"rft.ausuffix,omitempty"`
 	Corporation  string `json:"rft.aucorp,omitempty"`
+
+	// Role is one of the Role constants, empty means author.
+	Role string `json:"x.role,omitempty"`
+	// Identifiers, normalized, see NormalizeORCID, NormalizeGND and
+	// NormalizeISNI.
+	ORCID        string        `json:"x.orcid,omitempty"`
+	GND          string        `json:"x.gnd,omitempty"`
+	ISNI         string        `json:"x.isni,omitempty"`
+	Affiliations []Affiliation `json:"x.affiliations,omitempty"`
+}
+
+// Affiliation of an author, with an optional ROR identifier, e.g.
+// https://ror.org/03s7gtk40.
+type Affiliation struct {
+	Name string `json:"name,omitempty"`
+	ROR  string `json:"ror,omitempty"`
 }
 
 // String returns a formatted author string.
@@ -156,6 +178,28 @@ type IntermediateSchema struct {
 	// OpenAccess, refs. #8986, prototype
 	OpenAccess bool     `json:"x.oa,omitempty"`
 	License    []string `json:"x.license,omitempty"`
+
+	// Event is the conference or other event, e.g. for proceedings.
+	Event *Event `json:"x.event,omitempty"`
+
+	// Relations to other works, e.g. retractions or a parent book.
+	Relations []Relation `json:"x.relations,omitempty"`
+}
+
+// Event describes a conference or other event. Dates are in ISO8601 format
+// with the available precision, e.g. 2017-12-05, 2017-12 or 2017.
+type Event struct {
+	Name      string `json:"name,omitempty"`
+	Acronym   string `json:"acronym,omitempty"`
+	Number    string `json:"number,omitempty"`
+	Place     string `json:"place,omitempty"`
+	StartDate string `json:"start,omitempty"`
+	EndDate   string `json:"end,omitempty"`
+}
+
+// IsZero returns true, if the event has no values.
+func (e *Event) IsZero() bool {
+	return e == nil || *e == Event{}
 }
 
 // NewIntermediateSchema creates a new intermediate schema document with the
@@ -227,6 +271,9 @@ func (is *IntermediateSchema) Allfields() string {
 			is.Series,
 			is.ShortTitle,
 		}}
+	if is.Event != nil {
+		fields = append(fields, []string{is.Event.Name, is.Event.Acronym, is.Event.Place})
+	}
 
 	var buf bytes.Buffer
 	for _, f := range fields {
@@ -0,0 +1,389 @@
+package finc
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/kennygrant/sanitize"
+	"github.com/miku/span/assetutil"
+	"github.com/miku/span/container"
+)
+
+// Mapping is a declarative export of intermediate schema records into flat
+// documents, e.g. for a Solr schema. A mapping is a JSON file with a list of
+// target fields:
+//
+//     {
+//       "fields": [
+//         {"name": "id", "source": "finc.id", "type": "string"},
+//         {"name": "allfields", "source": "Allfields()", "type": "string"},
+//         {"name": "format_de15", "source": "finc.format", "map": "assets/finc/formats/de15.json"},
+//         {"name": "language", "source": "languages", "map": "assets/finc/iso-639-3-language.json", "keep": true},
+//         {"name": "access_facet", "value": "Electronic Resources", "type": "string"}
+//       ]
+//     }
+//
+// A source is the JSON key of a string or string slice field, e.g. rft.issn,
+// or the name of a derived value, see MappingFuncs. A value is a constant.
+// Values can be looked up in a string map, which is an asset path or a file;
+// values without a mapping are dropped, unless keep is set or there is a
+// default. The type is list (default), string, int or bool; empty values are
+// omitted, unless empty is set, then the field is written as an empty string
+// or a list with an empty string, like the former Solr5Vufind3 struct did.
+type Mapping struct {
+	Fields []MappingField `json:"fields"`
+}
+
+// MappingField describes a single target field.
+type MappingField struct {
+	Name    string `json:"name"`
+	Source  string `json:"source,omitempty"`
+	Value   string `json:"value,omitempty"`
+	Map     string `json:"map,omitempty"`
+	Keep    bool   `json:"keep,omitempty"`
+	Default string `json:"default,omitempty"`
+	Type    string `json:"type,omitempty"`
+	Empty   bool   `json:"empty,omitempty"`
+
+	lookup container.StringMap
+}
+
+// MappingFunc derives values from a record. Some values depend on whether the
+// full record is exported, e.g. the record type.
+type MappingFunc func(is IntermediateSchema, withFullrecord bool) []string
+
+// ORCIDPlaceholder stands for a missing ORCID in lists aligned with authors.
+const ORCIDPlaceholder = "-"
+
+// MappingFuncs are the derived values available as mapping sources.
+var MappingFuncs = map[string]MappingFunc{
+	"Allfields()":      func(is IntermediateSchema, _ bool) []string { return []string{is.Allfields()} },
+	"Imprint()":        func(is IntermediateSchema, _ bool) []string { return []string{is.Imprint()} },
+	"SortableTitle()":  func(is IntermediateSchema, _ bool) []string { return []string{is.SortableTitle()} },
+	"SortableAuthor()": func(is IntermediateSchema, _ bool) []string { return []string{is.SortableAuthor()} },
+	"ISSNList()":       func(is IntermediateSchema, _ bool) []string { return is.ISSNList() },
+	"ISBNList()":       func(is IntermediateSchema, _ bool) []string { return is.ISBNList() },
+	"Title()":          func(is IntermediateSchema, _ bool) []string { return []string{solrTitle(is)} },
+	"Year()":           func(is IntermediateSchema, _ bool) []string { return []string{strconv.Itoa(is.Date.Year())} },
+	"Date()":           func(is IntermediateSchema, _ bool) []string { return []string{is.Date.Format("2006-01-02")} },
+	"Series()":         func(is IntermediateSchema, _ bool) []string { return []string{is.JournalTitle, is.Series} },
+	"URL()":            func(is IntermediateSchema, _ bool) []string { return solrURL(is) },
+	"Classes()": func(is IntermediateSchema, _ bool) []string {
+		classes := container.NewStringSet()
+		for _, s := range is.Subjects {
+			for _, class := range SubjectMapping.LookupDefault(s, []string{}) {
+				classes.Add(class)
+			}
+		}
+		return classes.SortedValues()
+	},
+	"Authors()": func(is IntermediateSchema, _ bool) []string {
+		primary, _, _ := solrAuthors(is)
+		return primary
+	},
+	"AuthorSort()": func(is IntermediateSchema, _ bool) []string {
+		if primary, _, _ := solrAuthors(is); len(primary) > 0 {
+			return []string{strings.ToLower(primary[0])}
+		}
+		return nil
+	},
+	"SecondaryAuthors()": func(is IntermediateSchema, _ bool) []string {
+		_, secondary, _ := solrAuthors(is)
+		return secondary
+	},
+	"SecondaryAuthorRoles()": func(is IntermediateSchema, _ bool) []string {
+		_, _, roles := solrAuthors(is)
+		return roles
+	},
+	"AuthorFacet()": func(is IntermediateSchema, _ bool) (result []string) {
+		for _, author := range is.Authors {
+			result = append(result, AuthorReplacer.Replace(author.String()))
+		}
+		return result
+	},
+	"AuthorORCID()": func(is IntermediateSchema, _ bool) []string {
+		primary, _ := solrAuthorORCIDs(is)
+		return primary
+	},
+	"SecondaryAuthorORCID()": func(is IntermediateSchema, _ bool) []string {
+		_, secondary := solrAuthorORCIDs(is)
+		return secondary
+	},
+	"FacetAvail()": func(is IntermediateSchema, _ bool) []string {
+		if is.OpenAccess {
+			return []string{"Online", "Free"}
+		}
+		return []string{"Online"}
+	},
+	"Fullrecord()": func(is IntermediateSchema, withFullrecord bool) []string {
+		if !withFullrecord {
+			return []string{"blob:" + is.ID}
+		}
+		b, err := json.Marshal(is)
+		if err != nil {
+			return nil
+		}
+		return []string{string(b)}
+	},
+	"RecordType()": func(is IntermediateSchema, withFullrecord bool) []string {
+		if withFullrecord {
+			return []string{IntermediateSchemaRecordType}
+		}
+		return []string{AIRecordType}
+	},
+	"EventName()": func(is IntermediateSchema, _ bool) []string {
+		return eventValue(is, func(e *Event) string { return e.Name })
+	},
+	"EventAcronym()": func(is IntermediateSchema, _ bool) []string {
+		return eventValue(is, func(e *Event) string { return e.Acronym })
+	},
+	"EventNumber()": func(is IntermediateSchema, _ bool) []string {
+		return eventValue(is, func(e *Event) string { return e.Number })
+	},
+	"EventPlace()": func(is IntermediateSchema, _ bool) []string {
+		return eventValue(is, func(e *Event) string { return e.Place })
+	},
+	"EventStartDate()": func(is IntermediateSchema, _ bool) []string {
+		return eventValue(is, func(e *Event) string { return e.StartDate })
+	},
+	"EventEndDate()": func(is IntermediateSchema, _ bool) []string {
+		return eventValue(is, func(e *Event) string { return e.EndDate })
+	},
+	"RelationTypes()": func(is IntermediateSchema, _ bool) (result []string) {
+		for _, r := range is.Relations {
+			if r.ID != "" {
+				result = append(result, r.Type)
+			}
+		}
+		return result
+	},
+	"RelationIDs()": func(is IntermediateSchema, _ bool) (result []string) {
+		for _, r := range is.Relations {
+			if r.ID != "" {
+				result = append(result, r.ID)
+			}
+		}
+		return result
+	},
+	"ParentTitles()": func(is IntermediateSchema, _ bool) (result []string) {
+		for _, r := range is.RelationsByType(RelationIsPartOf) {
+			result = append(result, r.Title)
+		}
+		return result
+	},
+	"Retracted()": func(is IntermediateSchema, _ bool) []string { return []string{strconv.FormatBool(is.IsRetracted())} },
+	"OpenURL()":   func(is IntermediateSchema, _ bool) []string { return []string{ContextObject(is).KEV()} },
+}
+
+// eventValue returns a single event value or nothing, if there is no event.
+func eventValue(is IntermediateSchema, f func(*Event) string) []string {
+	if is.Event == nil {
+		return nil
+	}
+	return []string{f(is.Event)}
+}
+
+// solrTitle returns the sanitized book or article title.
+func solrTitle(is IntermediateSchema) string {
+	if is.BookTitle != "" {
+		return sanitize.HTML(is.BookTitle)
+	}
+	return sanitize.HTML(is.ArticleTitle)
+}
+
+// solrURL returns the links and a DOI link, if there is a DOI, but no link
+// contains it, refs. #8709, #12127, GH #9.
+func solrURL(is IntermediateSchema) []string {
+	urls := append([]string(nil), is.URL...)
+	if is.DOI == "" {
+		return urls
+	}
+	for _, u := range urls {
+		if strings.Contains(u, "doi") {
+			return urls
+		}
+	}
+	return append(urls, fmt.Sprintf("https://doi.org/%s", is.DOI))
+}
+
+// solrAuthors returns sanitized primary and secondary authors, the latter
+// with relator codes, refs #7092, gh #8, refs #12310.
+func solrAuthors(is IntermediateSchema) (primary, secondary, roles []string) {
+	for _, author := range is.Authors {
+		sanitized := AuthorReplacer.Replace(author.String())
+		if sanitized == "" {
+			continue
+		}
+		if author.IsPrimary() {
+			primary = append(primary, sanitized)
+		} else {
+			secondary = append(secondary, sanitized)
+			roles = append(roles, RelatorCode(author.Role))
+		}
+	}
+	return primary, secondary, roles
+}
+
+// solrAuthorORCIDs returns the ORCIDs of primary and secondary authors,
+// aligned with the author lists of solrAuthors. Authors without ORCID get
+// ORCIDPlaceholder. A list is empty, if none of its authors has an ORCID.
+func solrAuthorORCIDs(is IntermediateSchema) (primary, secondary []string) {
+	var found [2]bool
+	for _, author := range is.Authors {
+		if AuthorReplacer.Replace(author.String()) == "" {
+			continue
+		}
+		orcid := author.ORCID
+		if orcid == "" {
+			orcid = ORCIDPlaceholder
+		}
+		if author.IsPrimary() {
+			primary = append(primary, orcid)
+			found[0] = found[0] || author.ORCID != ""
+		} else {
+			secondary = append(secondary, orcid)
+			found[1] = found[1] || author.ORCID != ""
+		}
+	}
+	if !found[0] {
+		primary = nil
+	}
+	if !found[1] {
+		secondary = nil
+	}
+	return primary, secondary
+}
+
+// loadMappingFile reads a file, paths starting with assets/ are loaded as
+// assets, so they can be overridden with assetutil.SetAssets.
+func loadMappingFile(path string) ([]byte, error) {
+	if strings.HasPrefix(path, "assets/") {
+		return assetutil.Load(path)
+	}
+	return ioutil.ReadFile(path)
+}
+
+// LoadMapping reads a mapping from a file or, if there is no such file,
+// loads the built-in mapping of that name (assets/finc/mappings). Lookup maps
+// are loaded at once.
+func LoadMapping(name string) (*Mapping, error) {
+	b, err := ioutil.ReadFile(name)
+	if os.IsNotExist(err) && !strings.ContainsAny(name, "/.") {
+		b, err = assetutil.Load(fmt.Sprintf("assets/finc/mappings/%s.json", name))
+	}
+	if err != nil {
+		return nil, err
+	}
+	var m Mapping
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	for i, f := range m.Fields {
+		if f.Name == "" {
+			return nil, fmt.Errorf("%s: field %d without name", name, i)
+		}
+		if f.Source == "" && f.Value == "" {
+			return nil, fmt.Errorf("%s: %s: source or value required", name, f.Name)
+		}
+		if strings.HasSuffix(f.Source, "()") {
+			if _, ok := MappingFuncs[f.Source]; !ok {
+				return nil, fmt.Errorf("%s: %s: unknown function %s", name, f.Name, f.Source)
+			}
+		} else if f.Source != "" {
+			if _, ok := fieldIndex[f.Source]; !ok {
+				return nil, fmt.Errorf("%s: %s: unknown source %s", name, f.Name, f.Source)
+			}
+		}
+		switch f.Type {
+		case "", "list", "string", "int", "bool":
+		default:
+			return nil, fmt.Errorf("%s: %s: unknown type %s", name, f.Name, f.Type)
+		}
+		if f.Map == "" {
+			continue
+		}
+		b, err := loadMappingFile(f.Map)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %v", name, f.Name, err)
+		}
+		var lookup map[string]string
+		if err := json.Unmarshal(b, &lookup); err != nil {
+			return nil, fmt.Errorf("%s: %s: %v", name, f.Map, err)
+		}
+		m.Fields[i].lookup = container.StringMap(lookup)
+	}
+	return &m, nil
+}
+
+// values returns the non-empty values of a field for a record.
+func (f *MappingField) values(is IntermediateSchema, withFullrecord bool) (result []string) {
+	var values []string
+	switch {
+	case f.Value != "":
+		values = []string{f.Value}
+	case strings.HasSuffix(f.Source, "()"):
+		values = MappingFuncs[f.Source](is, withFullrecord)
+	default:
+		values, _ = is.Field(f.Source)
+	}
+	for _, v := range values {
+		if f.lookup != nil {
+			mapped, ok := f.lookup[v]
+			switch {
+			case ok:
+				v = mapped
+			case f.Default != "":
+				v = f.Default
+			case !f.Keep:
+				v = ""
+			}
+		}
+		if v != "" {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// Document returns the mapped fields of a record. Fields with the same name
+// are merged.
+func (m *Mapping) Document(is IntermediateSchema, withFullrecord bool) map[string]interface{} {
+	doc := make(map[string]interface{})
+	for i := range m.Fields {
+		f := &m.Fields[i]
+		values := f.values(is, withFullrecord)
+		if len(values) == 0 {
+			if !f.Empty {
+				continue
+			}
+			values = []string{""}
+		}
+		switch f.Type {
+		case "string":
+			if _, ok := doc[f.Name]; !ok {
+				doc[f.Name] = values[0]
+			}
+		case "int":
+			if v, err := strconv.Atoi(values[0]); err == nil && v != 0 {
+				doc[f.Name] = v
+			}
+		case "bool":
+			if v, err := strconv.ParseBool(values[0]); err == nil && v {
+				doc[f.Name] = v
+			}
+		default:
+			existing, _ := doc[f.Name].([]string)
+			doc[f.Name] = append(existing, values...)
+		}
+	}
+	return doc
+}
+
+// Export fulfils the finc.Exporter interface.
+func (m *Mapping) Export(is IntermediateSchema, withFullrecord bool) ([]byte, error) {
+	return json.Marshal(m.Document(is, withFullrecord))
+}
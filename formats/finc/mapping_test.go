@@ -0,0 +1,132 @@
+package finc
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// TestSolr5vu3Mapping compares the built-in solr5vu3 mapping to the output
+// of the former Solr5Vufind3 struct exporter, recorded in
+// fixtures/solr5vu3.ldj for the records in fixtures/solr5vu3.is. Since then,
+// author_orcid contains a placeholder for each author without ORCID.
+func TestSolr5vu3Mapping(t *testing.T) {
+	m, err := LoadMapping("solr5vu3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open("../../fixtures/solr5vu3.is")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	g, err := os.Open("../../fixtures/solr5vu3.ldj")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer g.Close()
+
+	// Lists, that were built from sets in no particular order.
+	unordered := []string{"issn", "isbn", "finc_class_facet"}
+	normalize := func(doc map[string]interface{}) {
+		for _, key := range unordered {
+			if v, ok := doc[key].([]interface{}); ok {
+				sort.Slice(v, func(i, j int) bool { return v[i].(string) < v[j].(string) })
+			}
+		}
+		// Added with the mapping, refs. openurl.
+		delete(doc, "openurl")
+	}
+
+	dec, want := json.NewDecoder(bufio.NewReader(f)), json.NewDecoder(bufio.NewReader(g))
+	var n int
+	for {
+		var is IntermediateSchema
+		err := dec.Decode(&is)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		var expected, got map[string]interface{}
+		if err := want.Decode(&expected); err != nil {
+			t.Fatalf("%s: %v", is.ID, err)
+		}
+		b, err := m.Export(is, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal(b, &got); err != nil {
+			t.Fatal(err)
+		}
+		normalize(expected)
+		normalize(got)
+		for k, v := range expected {
+			if !reflect.DeepEqual(got[k], v) {
+				t.Errorf("%s: %s: got %v, want %v", is.ID, k, got[k], v)
+			}
+		}
+		for k, v := range got {
+			if _, ok := expected[k]; !ok {
+				t.Errorf("%s: %s: unexpected field with %v", is.ID, k, v)
+			}
+		}
+		n++
+	}
+	if n == 0 {
+		t.Fatal("no records compared")
+	}
+}
+
+func TestAuthorORCID(t *testing.T) {
+	is := IntermediateSchema{Authors: []Author{
+		{LastName: "Doe", ORCID: "0000-0002-1825-0097"},
+		{LastName: ""},
+		{LastName: "Roe"},
+		{LastName: "Poe", Role: RoleEditor},
+		{LastName: "Moe", Role: RoleTranslator},
+	}}
+	var tests = []struct {
+		source string
+		want   []string
+	}{
+		{"Authors()", []string{"Doe", "Roe"}},
+		{"AuthorORCID()", []string{"0000-0002-1825-0097", ORCIDPlaceholder}},
+		{"SecondaryAuthors()", []string{"Poe", "Moe"}},
+		{"SecondaryAuthorORCID()", nil},
+	}
+	for _, c := range tests {
+		if got := MappingFuncs[c.source](is, false); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: got %v, want %v", c.source, got, c.want)
+		}
+	}
+	is.Authors[4].ORCID = "0000-0002-1694-233X"
+	want := []string{ORCIDPlaceholder, "0000-0002-1694-233X"}
+	if got := MappingFuncs["SecondaryAuthorORCID()"](is, false); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestMappingFullrecord(t *testing.T) {
+	m, err := LoadMapping("solr5vu3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	is := IntermediateSchema{ID: "ai-1-x", SourceID: "1"}
+	doc := m.Document(is, true)
+	b, _ := json.Marshal(is)
+	if doc["fullrecord"] != string(b) {
+		t.Errorf("fullrecord: got %v, want %s", doc["fullrecord"], b)
+	}
+	if doc["recordtype"] != IntermediateSchemaRecordType {
+		t.Errorf("recordtype: got %v, want %s", doc["recordtype"], IntermediateSchemaRecordType)
+	}
+	if doc = m.Document(is, false); doc["fullrecord"] != "blob:ai-1-x" {
+		t.Errorf("fullrecord: got %v, want blob:ai-1-x", doc["fullrecord"])
+	}
+}
@@ -0,0 +1,305 @@
+package finc
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/miku/span"
+	"github.com/miku/span/encoding/marc21"
+)
+
+// MarcXML exports records as MARC-XML, one record element per line, without
+// a collection element.
+type MarcXML struct{}
+
+// Export returns a single MARC-XML record.
+func (s *MarcXML) Export(is IntermediateSchema, _ bool) ([]byte, error) {
+	return marc21.MarshalXML(MarcRecord(is))
+}
+
+// Marc21 exports records in the binary MARC 21 format. Records are self
+// delimiting, so no newline is added.
+type Marc21 struct{}
+
+// Export returns a single binary MARC record. Records, that are too long even
+// without added authors and subjects, are skipped.
+func (s *Marc21) Export(is IntermediateSchema, _ bool) ([]byte, error) {
+	r := MarcRecord(is)
+	if !marcFit(&r) {
+		return nil, span.Skip{Reason: fmt.Sprintf("RECORD_TOO_LONG %s", is.ID)}
+	}
+	return marc21.Marshal(r)
+}
+
+// SelfDelimiting marks binary MARC records as self delimiting.
+func (s *Marc21) SelfDelimiting() bool { return true }
+
+// marcMaxValue is the maximum length of a single value in bytes, the binary
+// format limits fields to 9999 bytes.
+const marcMaxValue = 8000
+
+// marcClip shortens a value to at most marcMaxValue bytes.
+func marcClip(s string) string {
+	if len(s) <= marcMaxValue {
+		return s
+	}
+	s = s[:marcMaxValue]
+	for !utf8.ValidString(s) {
+		s = s[:len(s)-1]
+	}
+	return s + "..."
+}
+
+// marcFit removes 700 and then 653 fields from the end, until the record
+// fits into the binary format, e.g. for articles with thousands of authors.
+// The number of omitted fields is noted in a 500 field. It returns false, if
+// the record is still too long.
+func marcFit(r *marc21.Record) bool {
+	if r.Len() <= marc21.MaxRecordLength {
+		return true
+	}
+	// Leave room for the note.
+	excess := r.Len() - marc21.MaxRecordLength + 128
+	omit := make(map[int]bool)
+	var authors, subjects int
+	for _, tag := range []string{"700", "653"} {
+		for i := len(r.Datafields) - 1; i >= 0 && excess > 0; i-- {
+			if f := r.Datafields[i]; f.Tag == tag {
+				excess -= 12 + f.Len()
+				omit[i] = true
+				if tag == "700" {
+					authors++
+				} else {
+					subjects++
+				}
+			}
+		}
+	}
+	var parts []string
+	if authors > 0 {
+		parts = append(parts, fmt.Sprintf("%d further contributors", authors))
+	}
+	if subjects > 0 {
+		parts = append(parts, fmt.Sprintf("%d subjects", subjects))
+	}
+	note := marc21.Datafield{Tag: "500", Ind1: " ", Ind2: " ", Subfields: []marc21.Subfield{
+		marc21.Sub("a", fmt.Sprintf("Record shortened, %s omitted", strings.Join(parts, " and "))),
+	}}
+	var fields []marc21.Datafield
+	for i, f := range r.Datafields {
+		if omit[i] {
+			continue
+		}
+		if note.Tag != "" && f.Tag > note.Tag {
+			fields = append(fields, note)
+			note.Tag = ""
+		}
+		fields = append(fields, f)
+	}
+	if note.Tag != "" {
+		fields = append(fields, note)
+	}
+	r.Datafields = fields
+	return r.Len() <= marc21.MaxRecordLength
+}
+
+// marcLevels maps kinds of works to the bibliographic level in leader
+// position 7, component parts are a (monograph) or b (serial).
+var marcLevels = map[string]string{
+	kindArticle:    "b",
+	kindChapter:    "a",
+	kindConference: "a",
+	kindBook:       "m",
+	kindThesis:     "m",
+	kindReport:     "m",
+	kindStandard:   "m",
+	kindDataset:    "m",
+	kindGeneric:    "m",
+}
+
+// marcAuthor returns subfields for an author in a 100 or 700 field.
+func marcAuthor(author Author) []marc21.Subfield {
+	name := author.String()
+	if author.LastName != "" && author.Suffix != "" {
+		name = fmt.Sprintf("%s, %s", name, author.Suffix)
+	}
+	subfields := []marc21.Subfield{marc21.Sub("a", name)}
+	if !author.IsPrimary() {
+		subfields = append(subfields, marc21.Sub("e", author.Role))
+	}
+	if author.ORCID != "" {
+		subfields = append(subfields, marc21.Sub("0", "(orcid)"+author.ORCID))
+	}
+	if author.GND != "" {
+		subfields = append(subfields, marc21.Sub("0", "(DE-588)"+author.GND))
+	}
+	if len(author.Affiliations) > 0 {
+		subfields = append(subfields, marc21.Sub("u", author.Affiliations[0].Name))
+	}
+	return append(subfields, marc21.Sub("4", RelatorCode(author.Role)))
+}
+
+// MarcRecord maps an intermediate schema record to MARC 21 with the following
+// profile:
+//
+//     Leader  type a, level by kind of work: b (article), a (chapter,
+//             conference paper) or m (book and others)
+//     001     finc.id
+//     007     cr (online resource)
+//     008     date type s, year, form of item o, language
+//     020 $a  ISBN and EISBN
+//     022 $a  ISSN and EISSN
+//     024 $a  DOI, $2 doi
+//     041 $a  languages
+//     100 $a  first author, $0 ORCID and GND, $u affiliation, $4 relator
+//     110 $a  corporate author, if there is no personal author
+//     245 $a  title, $b subtitle
+//     250 $a  edition
+//     264 $a  place, $b publisher, $c year
+//     300 $a  number of pages
+//     490 $a  series
+//     520 $a  abstract, clipped
+//     653 $a  subjects
+//     700 $a  further authors, editors ($e editor) and translators
+//     711 $a  event, $c place, $d date
+//     773 $t  host title, $g volume, issue, pages, $x ISSN, $z ISBN
+//     856 $u  links, $z Open Access
+//     912 $a  labels, usually ISIL
+//     980 $a  record id, $b source id, $c mega collection
+//
+func MarcRecord(is IntermediateSchema) marc21.Record {
+	kind := citationKind(is)
+	r := marc21.Record{Leader: fmt.Sprintf("00000na%s a2200000 u 4500", marcLevels[kind])}
+	r.AddControlfield("001", is.ID)
+	r.AddControlfield("007", "cr")
+
+	year, _, _ := dateParts(is)
+	if year == "" {
+		year = "uuuu"
+	}
+	lang := "und"
+	if len(is.Languages) > 0 && len(is.Languages[0]) == 3 {
+		lang = is.Languages[0]
+	}
+	r.AddControlfield("008", fmt.Sprintf("||||||s%s    xx |||||o|||||||||||%s d", year, lang))
+
+	for _, isbn := range identifiers(is.ISBN, is.EISBN) {
+		r.AddDatafield("020", "", "", marc21.Sub("a", isbn))
+	}
+	issns := identifiers(is.ISSN, is.EISSN)
+	for _, issn := range issns {
+		r.AddDatafield("022", "", "", marc21.Sub("a", issn))
+	}
+	if is.DOI != "" {
+		r.AddDatafield("024", "7", "", marc21.Sub("a", is.DOI), marc21.Sub("2", "doi"))
+	}
+	for _, lang := range is.Languages {
+		r.AddDatafield("041", "", "", marc21.Sub("a", lang))
+	}
+
+	// The first primary author goes into 100 or 110, all others into 700,
+	// added later to keep the fields in order.
+	var hasMain bool
+	var added []Author
+	for _, author := range is.Authors {
+		switch {
+		case !hasMain && author.IsPrimary() && author.Corporation != "" && author.LastName == "":
+			r.AddDatafield("110", "2", "", marc21.Sub("a", author.Corporation), marc21.Sub("4", "aut"))
+			hasMain = true
+		case !hasMain && author.IsPrimary():
+			r.AddDatafield("100", "1", "", marcAuthor(author)...)
+			hasMain = true
+		default:
+			added = append(added, author)
+		}
+	}
+
+	title, subtitle := is.ArticleTitle, is.ArticleSubtitle
+	if title == "" || (kind == kindBook && is.BookTitle != "") {
+		title = is.BookTitle
+	}
+	ind1 := "0"
+	if hasMain {
+		ind1 = "1"
+	}
+	r.AddDatafield("245", ind1, "0", marc21.Sub("a", title), marc21.Sub("b", subtitle))
+	r.AddDatafield("250", "", "", marc21.Sub("a", is.Edition))
+	r.AddDatafield("264", "", "1",
+		marc21.Sub("a", strings.Join(is.Places, " ; ")),
+		marc21.Sub("b", strings.Join(is.Publishers, " ; ")),
+		marc21.Sub("c", strings.Replace(year, "uuuu", "", 1)))
+	if is.PageCount != "" && strings.Trim(is.PageCount, "0") != "" {
+		r.AddDatafield("300", "", "", marc21.Sub("a", is.PageCount+" pages"))
+	}
+	r.AddDatafield("490", "0", "", marc21.Sub("a", is.Series))
+	r.AddDatafield("520", "", "", marc21.Sub("a", marcClip(is.Abstract)))
+	for _, subject := range is.Subjects {
+		r.AddDatafield("653", "", "", marc21.Sub("a", subject))
+	}
+	for _, author := range added {
+		r.AddDatafield("700", "1", "", marcAuthor(author)...)
+	}
+	if is.Event != nil {
+		date := is.Event.StartDate
+		if is.Event.EndDate != "" && is.Event.EndDate != date {
+			date = date + "/" + is.Event.EndDate
+		}
+		r.AddDatafield("711", "2", "",
+			marc21.Sub("a", is.Event.Name),
+			marc21.Sub("n", is.Event.Number),
+			marc21.Sub("c", is.Event.Place),
+			marc21.Sub("d", date))
+	}
+
+	if host := containerTitle(is); host != "" {
+		var parts []string
+		var volume string
+		if is.Volume != "" {
+			volume = "Vol. " + is.Volume
+		}
+		if year != "uuuu" {
+			volume = strings.TrimSpace(volume + " (" + year + ")")
+		}
+		if volume != "" {
+			parts = append(parts, volume)
+		}
+		if is.Issue != "" {
+			parts = append(parts, "no. "+is.Issue)
+		}
+		switch start, end := pageRange(is); {
+		case start != "" && end != "" && start != end:
+			parts = append(parts, "p. "+start+"-"+end)
+		case start != "":
+			parts = append(parts, "p. "+start)
+		}
+		subfields := []marc21.Subfield{
+			marc21.Sub("t", host),
+			marc21.Sub("g", strings.Join(parts, ", ")),
+		}
+		if len(issns) > 0 {
+			subfields = append(subfields, marc21.Sub("x", issns[0]))
+		}
+		for _, isbn := range identifiers(is.ISBN, is.EISBN) {
+			subfields = append(subfields, marc21.Sub("z", isbn))
+		}
+		r.AddDatafield("773", "0", "8", subfields...)
+	}
+
+	for _, u := range is.URL {
+		if is.OpenAccess {
+			r.AddDatafield("856", "4", "0", marc21.Sub("u", u), marc21.Sub("z", "Open Access"))
+		} else {
+			r.AddDatafield("856", "4", "0", marc21.Sub("u", u))
+		}
+	}
+	for _, label := range is.Labels {
+		r.AddDatafield("912", "", "", marc21.Sub("a", label))
+	}
+	subfields := []marc21.Subfield{marc21.Sub("a", is.RecordID), marc21.Sub("b", is.SourceID)}
+	for _, c := range is.MegaCollections {
+		subfields = append(subfields, marc21.Sub("c", c))
+	}
+	r.AddDatafield("980", "", "", subfields...)
+	return r
+}
@@ -0,0 +1,171 @@
+package finc
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/miku/span"
+	"github.com/miku/span/encoding/marc21"
+)
+
+// marcFields returns the data fields of a record with a given tag, formatted
+// as indicators and subfields, e.g. "1 $aDoe, Jane$4aut".
+func marcFields(r marc21.Record, tag string) (result []string) {
+	for _, f := range r.Datafields {
+		if f.Tag != tag {
+			continue
+		}
+		s := f.Ind1 + f.Ind2 + " "
+		for _, sf := range f.Subfields {
+			s += "$" + sf.Code + sf.Value
+		}
+		result = append(result, s)
+	}
+	return result
+}
+
+func TestMarcRecord(t *testing.T) {
+	article := IntermediateSchema{
+		ID:           "ai-1-x",
+		RecordID:     "x",
+		SourceID:     "1",
+		Format:       "ElectronicArticle",
+		Genre:        "article",
+		ArticleTitle: "On Things",
+		JournalTitle: "Journal of Things",
+		Authors: []Author{
+			{LastName: "Doe", FirstName: "Jane", ORCID: "0000-0002-1825-0097"},
+			{LastName: "Roe", FirstName: "Rick"},
+			{LastName: "Poe", FirstName: "Edgar", Role: RoleEditor},
+		},
+		RawDate:    "2019-02-03",
+		Volume:     "12",
+		Issue:      "3",
+		StartPage:  "10",
+		EndPage:    "20",
+		ISSN:       []string{"1234-5679"},
+		EISSN:      []string{"2049-3630"},
+		DOI:        "10.1/x",
+		URL:        []string{"https://doi.org/10.1/x"},
+		OpenAccess: true,
+		Languages:  []string{"eng"},
+		Labels:     []string{"DE-15", "DE-14"},
+	}
+	chapter := IntermediateSchema{
+		ID:           "ai-2-y",
+		RefType:      "CHAP",
+		ArticleTitle: "A Chapter",
+		BookTitle:    "A Book",
+		Authors:      []Author{{Corporation: "ACME Corp."}},
+		RawDate:      "2018",
+		StartPage:    "5",
+		ISBN:         []string{"9780804429573"},
+		URL:          []string{"http://x.org/y"},
+	}
+	var tests = []struct {
+		is     IntermediateSchema
+		leader string
+		c008   string
+		fields map[string][]string
+	}{
+		{
+			is:     article,
+			leader: "00000nab a2200000 u 4500",
+			c008:   "||||||s2019    xx |||||o|||||||||||eng d",
+			fields: map[string][]string{
+				"022": {"   $a1234-5679", "   $a2049-3630"},
+				"024": {"7  $a10.1/x$2doi"},
+				"100": {"1  $aDoe, Jane$0(orcid)0000-0002-1825-0097$4aut"},
+				"110": nil,
+				"245": {"10 $aOn Things"},
+				"700": {"1  $aRoe, Rick$4aut", "1  $aPoe, Edgar$eeditor$4edt"},
+				"773": {"08 $tJournal of Things$gVol. 12 (2019), no. 3, p. 10-20$x1234-5679"},
+				"856": {"40 $uhttps://doi.org/10.1/x$zOpen Access"},
+				"912": {"   $aDE-15", "   $aDE-14"},
+				"980": {"   $ax$b1"},
+			},
+		},
+		{
+			is:     chapter,
+			leader: "00000naa a2200000 u 4500",
+			c008:   "||||||s2018    xx |||||o|||||||||||und d",
+			fields: map[string][]string{
+				"020": {"   $a9780804429573"},
+				"022": nil,
+				"024": nil,
+				"100": nil,
+				"110": {"2  $aACME Corp.$4aut"},
+				"245": {"10 $aA Chapter"},
+				"773": {"08 $tA Book$g(2018), p. 5$z9780804429573"},
+				"856": {"40 $uhttp://x.org/y"},
+				"912": nil,
+			},
+		},
+		{
+			is:     IntermediateSchema{ID: "ai-3-z", Format: "Book", BookTitle: "Untitled"},
+			leader: "00000nam a2200000 u 4500",
+			c008:   "||||||suuuu    xx |||||o|||||||||||und d",
+			fields: map[string][]string{
+				"245": {"00 $aUntitled"},
+				"773": nil,
+			},
+		},
+	}
+	for _, c := range tests {
+		r := MarcRecord(c.is)
+		if r.Leader != c.leader {
+			t.Errorf("%s: leader got %q, want %q", c.is.ID, r.Leader, c.leader)
+		}
+		controls := make(map[string]string)
+		for _, f := range r.Controlfields {
+			controls[f.Tag] = f.Value
+		}
+		if controls["001"] != c.is.ID || controls["007"] != "cr" {
+			t.Errorf("%s: unexpected control fields: %v", c.is.ID, controls)
+		}
+		if controls["008"] != c.c008 || len(controls["008"]) != 40 {
+			t.Errorf("%s: 008 got %q, want %q", c.is.ID, controls["008"], c.c008)
+		}
+		for tag, want := range c.fields {
+			if got := marcFields(r, tag); !reflect.DeepEqual(got, want) {
+				t.Errorf("%s: %s got %q, want %q", c.is.ID, tag, got, want)
+			}
+		}
+	}
+}
+
+func TestMarc21Long(t *testing.T) {
+	is := IntermediateSchema{ID: "ai-1-x", ArticleTitle: "Measurement of Things", Subjects: []string{"Physics"}}
+	for i := 0; i < 3000; i++ {
+		is.Authors = append(is.Authors, Author{LastName: fmt.Sprintf("Author%04d", i), FirstName: "A. B."})
+	}
+	b, err := new(Marc21).Export(is, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b) > marc21.MaxRecordLength {
+		t.Errorf("record too long: %d", len(b))
+	}
+	if !strings.Contains(string(b), "Author0001") || strings.Contains(string(b), "Author2999") {
+		t.Errorf("expected first authors to be kept and last authors to be omitted")
+	}
+	if !strings.Contains(string(b), "further contributors omitted") {
+		t.Errorf("expected a note about omitted authors")
+	}
+	if !strings.Contains(string(b), "Physics") {
+		t.Errorf("subjects should be kept, if omitting authors is enough")
+	}
+
+	// Too long without any added authors.
+	is = IntermediateSchema{ID: "ai-1-y"}
+	for i := 0; i < 12; i++ {
+		is.URL = append(is.URL, fmt.Sprintf("http://x.org/%d/%s", i, strings.Repeat("x", 9000)))
+	}
+	if _, err := new(Marc21).Export(is, false); err == nil {
+		t.Error("expected error for overlong record")
+	} else if _, ok := err.(span.Skip); !ok {
+		t.Errorf("got %v, want span.Skip", err)
+	}
+}
@@ -0,0 +1,141 @@
+package finc
+
+import (
+	"encoding/json"
+
+	"github.com/miku/span/openurl"
+)
+
+// OpenURLReferrer identifies span as the source of OpenURL links.
+const OpenURLReferrer = "info:sid/finc.info:span"
+
+// openURLGenres maps kinds of works to OpenURL genres, book formats are used
+// for all kinds but articles.
+var openURLGenres = map[string]string{
+	kindArticle:    "article",
+	kindChapter:    "bookitem",
+	kindBook:       "book",
+	kindConference: "proceeding",
+	kindReport:     "report",
+	kindThesis:     "document",
+	kindStandard:   "document",
+	kindDataset:    "document",
+	kindGeneric:    "document",
+}
+
+// ContextObject returns an OpenURL context object for a record. Articles use
+// the journal format, all other works the book format.
+func ContextObject(is IntermediateSchema) *openurl.ContextObject {
+	kind := citationKind(is)
+	var c *openurl.ContextObject
+	switch {
+	case kind == kindArticle, kind == kindGeneric && is.JournalTitle != "":
+		c = openurl.New(openurl.FormatJournal)
+		c.Add("genre", "article")
+		c.Add("atitle", citationTitle(is))
+		c.Add("jtitle", is.JournalTitle)
+		c.Add("stitle", is.ShortTitle)
+		c.Add("issn", identifiers(is.ISSN)...)
+		c.Add("eissn", identifiers(is.EISSN)...)
+		c.Add("volume", is.Volume)
+		c.Add("issue", is.Issue)
+		c.Add("artnum", is.ArticleNumber)
+		c.Add("part", is.Part)
+		c.Add("quarter", is.Quarter)
+		c.Add("ssn", is.Season)
+	default:
+		c = openurl.New(openurl.FormatBook)
+		c.Add("genre", openURLGenres[kind])
+		switch kind {
+		case kindChapter, kindConference:
+			c.Add("atitle", citationTitle(is))
+			c.Add("btitle", containerTitle(is))
+		default:
+			c.Add("btitle", citationTitle(is))
+		}
+		c.Add("isbn", identifiers(is.ISBN, is.EISBN)...)
+		c.Add("issn", identifiers(is.ISSN, is.EISSN)...)
+		c.Add("edition", is.Edition)
+		c.Add("series", is.Series)
+		c.Add("tpages", is.PageCount)
+		c.Add("pub", is.Publishers...)
+		c.Add("place", is.Places...)
+	}
+	c.Referrer = OpenURLReferrer
+	if is.DOI != "" {
+		c.AddID("info:doi/" + is.DOI)
+	}
+	if year, month, day := dateParts(is); year != "" {
+		date := year
+		if month != "" {
+			date += "-" + month
+			if day != "" {
+				date += "-" + day
+			}
+		}
+		c.Add("date", date)
+	}
+	switch start, end := pageRange(is); {
+	case start != "" && end != "" && start != end:
+		c.Add("spage", start)
+		c.Add("epage", end)
+		c.Add("pages", start+"-"+end)
+	case start != "":
+		c.Add("spage", start)
+	}
+	for i, author := range authorsByRole(is, "") {
+		if i == 0 {
+			if author.LastName != "" {
+				c.Add("aulast", author.LastName)
+				c.Add("aufirst", author.FirstName)
+				c.Add("ausuffix", author.Suffix)
+			} else {
+				c.Add("aucorp", author.Corporation)
+			}
+		}
+		c.Add("au", author.String())
+	}
+	return c
+}
+
+// OpenURL exports the OpenURL context object of a record as JSON with a
+// link for each label, that has a link resolver, and a link for the default
+// resolver, if configured:
+//
+//     {"id": "ai-49-...", "kev": "url_ver=Z39.88-2004&...", "links": {"DE-15": "https://..."}}
+//
+type OpenURL struct {
+	Resolvers openurl.Resolvers `json:"-"`
+
+	ID    string            `json:"id"`
+	KEV   string            `json:"kev"`
+	URL   string            `json:"url,omitempty"`
+	Links map[string]string `json:"links,omitempty"`
+}
+
+// Export returns a single JSON object.
+func (s *OpenURL) Export(is IntermediateSchema, _ bool) ([]byte, error) {
+	c := ContextObject(is)
+	*s = OpenURL{Resolvers: s.Resolvers, ID: is.ID, KEV: c.KEV()}
+	if base, ok := s.Resolvers[""]; ok {
+		s.URL = c.URL(base)
+	}
+	// Only labels with their own resolver get a link, the default is in URL.
+	for _, label := range is.Labels {
+		if base, ok := s.Resolvers[label]; ok && label != "" {
+			if s.Links == nil {
+				s.Links = make(map[string]string)
+			}
+			s.Links[label] = c.URL(base)
+		}
+	}
+	return json.Marshal(s)
+}
+
+// COinS exports records as COinS, one HTML span per line.
+type COinS struct{}
+
+// Export returns a single span.
+func (s *COinS) Export(is IntermediateSchema, _ bool) ([]byte, error) {
+	return []byte(ContextObject(is).COinS()), nil
+}
@@ -0,0 +1,167 @@
+package finc
+
+import (
+	"encoding/json"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/miku/span/openurl"
+)
+
+var (
+	openURLArticle = IntermediateSchema{
+		ID:           "ai-1-x",
+		Genre:        "article",
+		ArticleTitle: "On Things",
+		JournalTitle: "Journal of Things",
+		Authors:      []Author{{LastName: "Doe", FirstName: "Jane"}, {LastName: "Roe", FirstName: "Rick"}},
+		RawDate:      "2019-02-03T10:00:00Z",
+		Volume:       "12",
+		Issue:        "3",
+		StartPage:    "10",
+		EndPage:      "20",
+		ISSN:         []string{"1234-5679"},
+		EISSN:        []string{"2049-3630"},
+		DOI:          "10.1/x",
+		Labels:       []string{"DE-15", "DE-14"},
+	}
+	openURLChapter = IntermediateSchema{
+		ID:           "ai-2-y",
+		RefType:      "CHAP",
+		ArticleTitle: "A Chapter",
+		BookTitle:    "A Book",
+		Authors:      []Author{{Corporation: "ACME Corp."}},
+		RawDate:      "2018",
+		StartPage:    "5",
+		ISBN:         []string{"9780804429573"},
+		Publishers:   []string{"Springer"},
+	}
+)
+
+func TestContextObject(t *testing.T) {
+	var tests = []struct {
+		is     IntermediateSchema
+		format string
+		ids    []string
+		values url.Values
+	}{
+		{
+			is:     openURLArticle,
+			format: openurl.FormatJournal,
+			ids:    []string{"info:doi/10.1/x"},
+			values: url.Values{
+				"genre":   {"article"},
+				"atitle":  {"On Things"},
+				"jtitle":  {"Journal of Things"},
+				"issn":    {"1234-5679"},
+				"eissn":   {"2049-3630"},
+				"volume":  {"12"},
+				"issue":   {"3"},
+				"date":    {"2019-02-03"},
+				"spage":   {"10"},
+				"epage":   {"20"},
+				"pages":   {"10-20"},
+				"aulast":  {"Doe"},
+				"aufirst": {"Jane"},
+				"au":      {"Doe, Jane", "Roe, Rick"},
+			},
+		},
+		{
+			is:     openURLChapter,
+			format: openurl.FormatBook,
+			values: url.Values{
+				"genre":  {"bookitem"},
+				"atitle": {"A Chapter"},
+				"btitle": {"A Book"},
+				"isbn":   {"9780804429573"},
+				"pub":    {"Springer"},
+				"date":   {"2018"},
+				"spage":  {"5"},
+				"aucorp": {"ACME Corp."},
+			},
+		},
+	}
+	for _, c := range tests {
+		co := ContextObject(c.is)
+		if co.Format != c.format {
+			t.Errorf("%s: format got %s, want %s", c.is.ID, co.Format, c.format)
+		}
+		if co.Referrer != OpenURLReferrer {
+			t.Errorf("%s: referrer got %s", c.is.ID, co.Referrer)
+		}
+		if !reflect.DeepEqual(co.IDs, c.ids) {
+			t.Errorf("%s: ids got %v, want %v", c.is.ID, co.IDs, c.ids)
+		}
+		if !reflect.DeepEqual(co.Values, c.values) {
+			t.Errorf("%s: values got %v, want %v", c.is.ID, co.Values, c.values)
+		}
+	}
+	if kev := ContextObject(openURLArticle).KEV(); !strings.Contains(kev, "&rft_id=info%3Adoi%2F10.1%2Fx&") {
+		t.Errorf("KEV without DOI: %s", kev)
+	}
+}
+
+func TestOpenURLExport(t *testing.T) {
+	resolvers := openurl.Resolvers{
+		"DE-15": "https://katalog.example.org/openurl",
+		"DE-1":  "https://other.example.org/openurl",
+		"":      "https://sfx.example.org/sfx?sid=x",
+	}
+	kev := ContextObject(openURLArticle).KEV()
+	var tests = []struct {
+		resolvers openurl.Resolvers
+		is        IntermediateSchema
+		url       string
+		links     map[string]string
+	}{
+		{
+			resolvers: resolvers,
+			is:        openURLArticle,
+			url:       "https://sfx.example.org/sfx?sid=x&" + kev,
+			links:     map[string]string{"DE-15": "https://katalog.example.org/openurl?" + kev},
+		},
+		{
+			resolvers: openurl.Resolvers{"DE-15": "https://katalog.example.org/openurl?"},
+			is:        openURLArticle,
+			links:     map[string]string{"DE-15": "https://katalog.example.org/openurl?" + kev},
+		},
+		{resolvers: nil, is: openURLArticle},
+	}
+	for _, c := range tests {
+		b, err := (&OpenURL{Resolvers: c.resolvers}).Export(c.is, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got struct {
+			ID    string            `json:"id"`
+			KEV   string            `json:"kev"`
+			URL   string            `json:"url"`
+			Links map[string]string `json:"links"`
+		}
+		if err := json.Unmarshal(b, &got); err != nil {
+			t.Fatal(err)
+		}
+		if got.ID != c.is.ID || got.KEV != kev {
+			t.Errorf("got id %s, kev %s", got.ID, got.KEV)
+		}
+		if got.URL != c.url {
+			t.Errorf("url got %s, want %s", got.URL, c.url)
+		}
+		if !reflect.DeepEqual(got.Links, c.links) {
+			t.Errorf("links got %v, want %v", got.Links, c.links)
+		}
+	}
+}
+
+func TestCOinS(t *testing.T) {
+	b, err := new(COinS).Export(openURLChapter, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prefix := `<span class="Z3988" title="url_ver=Z39.88-2004&amp;ctx_ver=Z39.88-2004&amp;`
+	if !strings.HasPrefix(string(b), prefix) || !strings.Contains(string(b), "rft.btitle=A+Book") {
+		t.Errorf("unexpected COinS: %s", b)
+	}
+}
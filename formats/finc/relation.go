@@ -0,0 +1,77 @@
+package finc
+
+import "strings"
+
+// Relation types. Most types follow the Crossref relation vocabulary and are
+// read from the record to the target, e.g. a retraction notice retracts the
+// original article and the original article is retracted by the notice.
+const (
+	RelationRetracts      = "retracts"
+	RelationIsRetractedBy = "is-retracted-by"
+	RelationCorrects      = "corrects"
+	RelationIsCorrectedBy = "is-corrected-by"
+	RelationUpdates       = "updates"
+	RelationIsUpdatedBy   = "is-updated-by"
+	RelationIsPreprintOf  = "is-preprint-of"
+	RelationHasPreprint   = "has-preprint"
+	RelationIsVersionOf   = "is-version-of"
+	RelationHasVersion    = "has-version"
+	RelationIsPartOf      = "is-part-of"
+	RelationHasPart       = "has-part"
+)
+
+// Relation points from a record to another work, e.g. a retracted article or
+// the book a chapter belongs to. The target is identified by an identifier
+// like a DOI or ISBN, a title or both.
+type Relation struct {
+	Type   string `json:"type"`
+	ID     string `json:"id,omitempty"`
+	IDType string `json:"id-type,omitempty"`
+	Title  string `json:"title,omitempty"`
+	// Label is a human readable description, e.g. "Retraction".
+	Label string `json:"label,omitempty"`
+	// Date of the relation, e.g. when an update was published.
+	Date string `json:"date,omitempty"`
+}
+
+// UpdateRelation returns the relation type for an update type, like the
+// Crossref update types retraction, erratum or new_version. The update may
+// point to the updated work (forward is false) or to the updating notice
+// (forward is true).
+func UpdateRelation(updateType string, forward bool) string {
+	var retraction, correction bool
+	switch strings.ToLower(strings.Replace(updateType, "-", "_", -1)) {
+	case "retraction", "partial_retraction", "withdrawal", "removal":
+		retraction = true
+	case "correction", "corrigendum", "erratum":
+		correction = true
+	}
+	switch {
+	case retraction && forward:
+		return RelationIsRetractedBy
+	case retraction:
+		return RelationRetracts
+	case correction && forward:
+		return RelationIsCorrectedBy
+	case correction:
+		return RelationCorrects
+	case forward:
+		return RelationIsUpdatedBy
+	}
+	return RelationUpdates
+}
+
+// RelationsByType returns all relations of a given type.
+func (is *IntermediateSchema) RelationsByType(typ string) (result []Relation) {
+	for _, r := range is.Relations {
+		if r.Type == typ {
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
+// IsRetracted returns true, if the record is retracted by another work.
+func (is *IntermediateSchema) IsRetracted() bool {
+	return len(is.RelationsByType(RelationIsRetractedBy)) > 0
+}
@@ -0,0 +1,89 @@
+package finc
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// RIS exports records in the RIS tagged format, as read by most citation
+// managers. The record reference type is used, if it is a valid RIS type.
+type RIS struct{}
+
+// risKindTypes maps kinds of works to RIS types.
+var risKindTypes = map[string]string{
+	kindArticle:    "JOUR",
+	kindChapter:    "CHAP",
+	kindBook:       "BOOK",
+	kindConference: "CPAPER",
+	kindReport:     "RPRT",
+	kindThesis:     "THES",
+	kindStandard:   "STAND",
+	kindDataset:    "DATA",
+	kindGeneric:    "GEN",
+}
+
+// Type returns the RIS reference type of a record.
+func (s *RIS) Type(is IntermediateSchema) string {
+	if risTypes.Contains(is.RefType) {
+		return is.RefType
+	}
+	return risKindTypes[citationKind(is)]
+}
+
+// Export returns a single RIS record.
+func (s *RIS) Export(is IntermediateSchema, _ bool) ([]byte, error) {
+	var buf bytes.Buffer
+
+	// tag writes a line for each non-empty value.
+	tag := func(tag string, values ...string) {
+		for _, v := range values {
+			v = strings.Join(strings.Fields(v), " ")
+			if v == "" {
+				continue
+			}
+			fmt.Fprintf(&buf, "%s  - %s\n", tag, v)
+		}
+	}
+	tag("TY", s.Type(is))
+	tag("ID", is.ID)
+	tag("TI", citationTitle(is))
+	if ct := containerTitle(is); ct != "" {
+		tag("T2", ct)
+	} else if is.Event != nil {
+		tag("T2", is.Event.Name)
+	}
+	tag("J2", is.ShortTitle)
+	tag("T3", is.Series)
+	for _, author := range authorsByRole(is, "") {
+		tag("AU", citationName(author))
+	}
+	for _, author := range authorsByRole(is, RoleEditor) {
+		tag("ED", citationName(author))
+	}
+	for _, author := range authorsByRole(is, RoleTranslator) {
+		tag("A4", citationName(author))
+	}
+	if year, month, day := dateParts(is); year != "" {
+		tag("PY", year)
+		tag("DA", fmt.Sprintf("%s/%s/%s/", year, month, day))
+	}
+	tag("VL", is.Volume)
+	tag("IS", is.Issue)
+	start, end := pageRange(is)
+	tag("SP", start)
+	tag("EP", end)
+	tag("ET", is.Edition)
+	tag("SN", identifiers(is.ISSN, is.EISSN, is.ISBN, is.EISBN)...)
+	tag("DO", is.DOI)
+	tag("UR", is.URL...)
+	tag("PB", is.Publishers...)
+	tag("CY", is.Places...)
+	tag("AB", is.Abstract)
+	tag("KW", is.Subjects...)
+	tag("LA", is.Languages...)
+	tag("DB", is.Database)
+	tag("DP", is.DataProvider)
+	buf.WriteString("ER  - \n")
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,84 @@
+package finc
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRISExport(t *testing.T) {
+	is := IntermediateSchema{
+		ID:              "ai-1-x",
+		RefType:         "JOUR",
+		ArticleTitle:    "On Things",
+		ArticleSubtitle: "A Study",
+		JournalTitle:    "Journal of Things",
+		Authors: []Author{
+			{LastName: "Doe", FirstName: "Jane"},
+			{Corporation: "ACME Corp."},
+			{LastName: "Roe", FirstName: "Rick", Role: RoleEditor},
+		},
+		RawDate:   "2019-02-03T10:00:00Z",
+		Volume:    "1",
+		Issue:     "2",
+		StartPage: "10",
+		EndPage:   "20",
+		ISSN:      []string{"1234-5678"},
+		EISSN:     []string{"1234-5678", "8765-4321"},
+		DOI:       "10.1/x",
+		URL:       []string{"http://x.org/1"},
+	}
+	want := `TY  - JOUR
+ID  - ai-1-x
+TI  - On Things: A Study
+T2  - Journal of Things
+AU  - Doe, Jane
+AU  - ACME Corp.
+ED  - Roe, Rick
+PY  - 2019
+DA  - 2019/02/03/
+VL  - 1
+IS  - 2
+SP  - 10
+EP  - 20
+SN  - 1234-5678
+SN  - 8765-4321
+DO  - 10.1/x
+UR  - http://x.org/1
+ER  - 
+`
+	b, err := new(RIS).Export(is, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", b, want)
+	}
+}
+
+func TestRISDate(t *testing.T) {
+	var tests = []struct {
+		raw  string
+		want []string
+	}{
+		{"2019-05-07", []string{"PY  - 2019", "DA  - 2019/05/07/"}},
+		{"2019-05-07T00:00:00Z", []string{"PY  - 2019", "DA  - 2019/05/07/"}},
+		{"2019-05", []string{"PY  - 2019", "DA  - 2019/05//"}},
+		{"2019", []string{"PY  - 2019", "DA  - 2019///"}},
+		{"", nil},
+	}
+	for _, c := range tests {
+		b, err := new(RIS).Export(IntermediateSchema{ID: "ai-1-x", RawDate: c.raw}, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, line := range strings.Split(string(b), "\n") {
+			if strings.HasPrefix(line, "PY") || strings.HasPrefix(line, "DA") {
+				got = append(got, line)
+			}
+		}
+		if strings.Join(got, "|") != strings.Join(c.want, "|") {
+			t.Errorf("%q: got %q, want %q", c.raw, got, c.want)
+		}
+	}
+}
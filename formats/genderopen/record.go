@@ -11,9 +11,28 @@ import (
 	"time"
 
 	"github.com/miku/span"
+	"github.com/miku/span/formats"
 	"github.com/miku/span/formats/finc"
 )
 
+const (
+	// SourceID for internal bookkeeping.
+	SourceID = "162"
+	// Collection for finc.mega_collection.
+	Collection = "Gender Open"
+)
+
+func init() {
+	formats.Register(formats.Format{
+		Name:        "genderopen",
+		Shape:       formats.XML,
+		New:         func() interface{} { return new(Record) },
+		SourceID:    SourceID,
+		Collection:  Collection,
+		Description: "GenderOpen, Dublin Core via OAI",
+	})
+}
+
 // Record was generated 2018-05-11 14:30:28 by tir on sol.
 type Record struct {
 	XMLName xml.Name `xml:"Record"`
@@ -112,11 +131,11 @@ func stringsContainsAny(v string, vals []string) bool {
 func (record Record) ToIntermediateSchema() (*finc.IntermediateSchema, error) {
 	output := finc.NewIntermediateSchema()
 
-	output.SourceID = "162"
+	output.SourceID = SourceID
 	encodedRecordID := base64.RawURLEncoding.EncodeToString([]byte(record.Header.Identifier.Text))
 	output.RecordID = encodedRecordID
 	output.ID = fmt.Sprintf("ai-%s-%s", output.SourceID, output.RecordID)
-	output.MegaCollections = append(output.MegaCollections, "Gender Open")
+	output.MegaCollections = append(output.MegaCollections, Collection)
 	output.Genre = "article"
 	output.RefType = "EJOUR"
 	output.Format = "ElectronicArticle"
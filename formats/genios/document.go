@@ -34,6 +34,7 @@ import (
 	"github.com/miku/span"
 	"github.com/miku/span/assetutil"
 	"github.com/miku/span/container"
+	"github.com/miku/span/formats"
 	"github.com/miku/span/formats/finc"
 )
 
@@ -56,6 +57,17 @@ const (
 	maxTitleLength  = 4096
 )
 
+func init() {
+	formats.Register(formats.Format{
+		Name:        "genios",
+		Shape:       formats.XML,
+		New:         func() interface{} { return new(Document) },
+		SourceID:    SourceID,
+		Collection:  Collection,
+		Description: "GENIOS Profile XML",
+	})
+}
+
 // Document represents a Genios document.
 type Document struct {
 	ID               string   `xml:"ID,attr"`
@@ -285,6 +297,7 @@ func (doc Document) ToIntermediateSchema() (*finc.IntermediateSchema, error) {
 	// Note DB name as well as package name (Wiwi, Sowi, Recht, etc.) as well
 	// as kind, which - a bit confusingly - is also package in licensing terms (FZS).
 	output.Packages = append([]string{doc.DB}, prefixedPackageNames...)
+	output.Database = doc.DB
 
 	// 2018-06-01, Modules are added, (1) add them in addition to existing
 	// package names, later XXX: (2) remove own tags.
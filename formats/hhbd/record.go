@@ -9,10 +9,28 @@ import (
 	"time"
 
 	"github.com/miku/span"
-
+	"github.com/miku/span/formats"
 	"github.com/miku/span/formats/finc"
 )
 
+const (
+	// SourceID for internal bookkeeping.
+	SourceID = "107"
+	// Collection for finc.mega_collection.
+	Collection = "sid-107-col-heidelberg"
+)
+
+func init() {
+	formats.Register(formats.Format{
+		Name:        "hhbd",
+		Shape:       formats.XML,
+		New:         func() interface{} { return new(Record) },
+		SourceID:    SourceID,
+		Collection:  Collection,
+		Description: "Heidelberger historische Bestaende digital, OAI",
+	})
+}
+
 var datePattern = regexp.MustCompile(`[012][0-9][0-9][0-9]`)
 
 func uniqueStrings(s []string) (result []string) {
@@ -118,10 +136,10 @@ func (record Record) ToIntermediateSchema() (*finc.IntermediateSchema, error) {
 	var err error
 	output := finc.NewIntermediateSchema()
 	output.RecordID = record.Header.Identifier.Text
-	output.SourceID = "107"
+	output.SourceID = SourceID
 	output.ID = fmt.Sprintf("ai-%s-%s", output.SourceID, base64.RawURLEncoding.EncodeToString([]byte(output.RecordID)))
 	output.ArticleTitle = record.Metadata.Dc.Title.Text
-	output.MegaCollections = []string{"sid-107-col-heidelberg"}
+	output.MegaCollections = []string{Collection}
 
 	// XXX: Guess.
 	output.Format = "Manuscript"
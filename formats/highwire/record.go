@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/miku/span"
+	"github.com/miku/span/formats"
 	"github.com/miku/span/formats/finc"
 )
 
@@ -19,6 +20,16 @@ const (
 	DefaultRefType   = "EJOUR"
 )
 
+func init() {
+	formats.Register(formats.Format{
+		Name:        "highwire",
+		Shape:       formats.XML,
+		New:         func() interface{} { return new(Record) },
+		SourceID:    SourceIdentifier,
+		Description: "HighWire, Dublin Core via OAI",
+	})
+}
+
 // Record is a sketch for highwire XML.
 type Record struct {
 	XMLName xml.Name `xml:"Record"`
@@ -1,8 +1,34 @@
 package ieee
 
-import "encoding/xml"
+import (
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
-// XPublication was generated 2018-07-27 13:48:36 by tir on hayiti. XXX: Transistion to this schema.
+	"github.com/miku/span"
+	"github.com/miku/span/container"
+	"github.com/miku/span/formats"
+	"github.com/miku/span/formats/finc"
+)
+
+func init() {
+	formats.Register(formats.Format{
+		Name:        "ieee-next",
+		Shape:       formats.XML,
+		New:         func() interface{} { return new(XPublication) },
+		SourceID:    SourceID,
+		Collection:  Collection,
+		Description: "IEEE IDAMS Exchange, current schema with conferences and standards",
+	})
+}
+
+// XPublication was generated 2018-07-27 13:48:36 by tir on hayiti. It
+// follows the current IEEE exchange format and is used by the ieee-next
+// format.
 type XPublication struct {
 	XMLName xml.Name `xml:"publication"`
 	Text    string   `xml:",chardata"`
@@ -695,3 +721,379 @@ type XPublication struct {
 		Text string `xml:",chardata"` // IEEE Standard for Local a...
 	} `xml:"standardsfamilytitle"`
 }
+
+// Publication types, as found in publicationtype.
+const (
+	typeConference = "conference"
+	typeStandard   = "standard"
+	typeBook       = "book"
+)
+
+// publicationType returns a normalized publication type, conference,
+// standard, book or an empty string for periodicals.
+func (p XPublication) publicationType() string {
+	t := strings.ToLower(p.Publicationinfo.Publicationtype.Text)
+	switch {
+	case strings.Contains(t, "conference"):
+		return typeConference
+	case strings.Contains(t, "standard"):
+		return typeStandard
+	case strings.Contains(t, "book") || strings.Contains(t, "course"):
+		return typeBook
+	}
+	return ""
+}
+
+// parseDate parses year, month and day, month may be a number or a
+// (possibly abbreviated) month name, e.g. "Aug.". Missing month or day
+// default to one.
+func parseDate(year, month, day string) (time.Time, error) {
+	y, err := strconv.Atoi(strings.TrimSpace(year))
+	if err != nil {
+		return time.Time{}, ErrNoDate
+	}
+	m, d := 1, 1
+	month = strings.TrimSpace(strings.Trim(month, ". "))
+	if v, err := strconv.Atoi(month); err == nil {
+		if v > 0 && v < 13 {
+			m = v
+		}
+	} else if len(month) >= 3 {
+		if t, err := time.Parse("Jan", strings.Title(strings.ToLower(month[:3]))); err == nil {
+			m = int(t.Month())
+		}
+	}
+	if v, err := strconv.Atoi(strings.TrimSpace(day)); err == nil && v > 0 && v < 32 {
+		d = v
+	}
+	return time.Date(y, time.Month(m), d, 0, 0, 0, 0, time.UTC), nil
+}
+
+// eventDate formats a conference date with the available precision, e.g.
+// 2017-12-05, 2017-12 or 2017.
+func eventDate(year, month, day string) string {
+	t, err := parseDate(year, month, day)
+	if err != nil {
+		return ""
+	}
+	switch {
+	case strings.TrimSpace(month) == "":
+		return t.Format("2006")
+	case strings.TrimSpace(day) == "":
+		return t.Format("2006-01")
+	}
+	return t.Format("2006-01-02")
+}
+
+// eventPlace joins conference location and country, unless the location
+// already mentions the country.
+func eventPlace(location, country string) string {
+	location, country = strings.TrimSpace(location), strings.TrimSpace(country)
+	if country == "" || strings.Contains(location, country) {
+		return location
+	}
+	if location == "" {
+		return country
+	}
+	return fmt.Sprintf("%s, %s", location, country)
+}
+
+// Event returns the conference, or nil, if there is no conference title.
+func (p XPublication) Event() *finc.Event {
+	cg := p.Publicationinfo.Confgroup
+	event := &finc.Event{
+		Name:  strings.TrimSpace(cg.Conftitle.Text),
+		Place: eventPlace(cg.Conflocation.Text, cg.Confcountry.Text),
+	}
+	if event.Name == "" {
+		return nil
+	}
+	for _, acronym := range p.Publicationinfo.Acronym {
+		if v := strings.TrimSpace(acronym.Text); v != "" {
+			event.Acronym = v
+			break
+		}
+	}
+	for _, cd := range cg.Confdate {
+		switch cd.Confdatetype {
+		case "Start":
+			event.StartDate = eventDate(cd.Year.Text, cd.Month.Text, cd.Day.Text)
+		case "End":
+			event.EndDate = eventDate(cd.Year.Text, cd.Month.Text, cd.Day.Text)
+		}
+	}
+	return event
+}
+
+// Date returns the original publication date, the electronic publication
+// date or the first date found, in that order, or the volume year.
+func (p XPublication) Date() (time.Time, error) {
+	dates := p.Volume.Article.Articleinfo.Date
+	for _, typ := range []string{"OriginalPub", "ePub", ""} {
+		for _, dt := range dates {
+			if typ != "" && dt.Datetype != typ {
+				continue
+			}
+			if t, err := parseDate(dt.Year.Text, dt.Month.Text, dt.Day.Text); err == nil {
+				return t, nil
+			}
+		}
+	}
+	return parseDate(p.Volume.Volumeinfo.Year.Text, "", "")
+}
+
+// Authors returns authors and editors with ORCID and affiliation, preferring
+// the unicode variants of names.
+func (p XPublication) Authors() []finc.Author {
+	var authors []finc.Author
+	for _, author := range p.Volume.Article.Articleinfo.Authorgroup.Author {
+		a := finc.Author{
+			FirstName: strings.TrimSpace(author.Firstname.Text),
+			LastName:  strings.TrimSpace(author.Surname.Text),
+			Suffix:    strings.TrimSpace(author.Lineage.Text),
+			Role:      finc.ParseRole(author.Authortype.Text),
+			ORCID:     finc.NormalizeORCID(author.Orcid.Text),
+		}
+		if v := strings.TrimSpace(author.Unicodefirstname.Text); v != "" {
+			a.FirstName = v
+		}
+		if v := strings.TrimSpace(author.Unicodesurname.Text); v != "" {
+			a.LastName = v
+		}
+		if a.FirstName == "" && a.LastName == "" {
+			a.Name = strings.TrimSpace(author.Normname.Text)
+		}
+		if a.Name == "" && a.FirstName == "" && a.LastName == "" {
+			continue
+		}
+		if v := strings.TrimSpace(author.Affiliation.Text); v != "" {
+			a.Affiliations = []finc.Affiliation{{Name: v}}
+		}
+		authors = append(authors, a)
+	}
+	return authors
+}
+
+// Keywords returns keyword terms grouped by keyword type, e.g. "IEEE Keywords",
+// "INSPEC: Controlled Indexing" or "Author Keywords".
+func (p XPublication) Keywords() map[string][]string {
+	keywords := make(map[string][]string)
+	for _, set := range p.Volume.Article.Articleinfo.Keywordset {
+		for _, kw := range set.Keyword {
+			if term := strings.TrimSpace(kw.Keywordterm.Text); term != "" {
+				keywords[set.Keywordtype] = append(keywords[set.Keywordtype], term)
+			}
+		}
+	}
+	return keywords
+}
+
+// Subjects returns keywords of all types, controlled vocabularies first.
+func (p XPublication) Subjects() []string {
+	var (
+		keywords = p.Keywords()
+		seen     = container.NewStringSet()
+		types    []string
+		subjects []string
+	)
+	for typ := range keywords {
+		types = append(types, typ)
+	}
+	rank := func(typ string) int {
+		t := strings.ToLower(typ)
+		switch {
+		case strings.Contains(t, "ieee"):
+			return 0
+		case strings.Contains(t, "inspec") && !strings.Contains(t, "non-controlled"):
+			return 1
+		case strings.Contains(t, "mesh"):
+			return 2
+		case strings.Contains(t, "author"):
+			return 3
+		}
+		return 4
+	}
+	sort.Slice(types, func(i, j int) bool {
+		if rank(types[i]) != rank(types[j]) {
+			return rank(types[i]) < rank(types[j])
+		}
+		return types[i] < types[j]
+	})
+	for _, typ := range types {
+		for _, term := range keywords[typ] {
+			if seen.Add(strings.ToLower(term)) {
+				subjects = append(subjects, term)
+			}
+		}
+	}
+	for _, code := range p.Publicationinfo.Icscodes.CodeTerm {
+		if term := strings.TrimSpace(code.Text); term != "" && seen.Add(strings.ToLower(term)) {
+			subjects = append(subjects, term)
+		}
+	}
+	return subjects
+}
+
+// Abstract returns the regular abstract, or the first non-empty abstract.
+func (p XPublication) Abstract() string {
+	var first string
+	for _, a := range p.Volume.Article.Articleinfo.Abstract {
+		text := strings.TrimSpace(a.Text)
+		if text == "" {
+			continue
+		}
+		if strings.EqualFold(a.Abstracttype, "Regular") {
+			return text
+		}
+		if first == "" {
+			first = text
+		}
+	}
+	return first
+}
+
+// ToIntermediateSchema converts a publication, handling periodicals,
+// conference proceedings, standards and books.
+func (p XPublication) ToIntermediateSchema() (*finc.IntermediateSchema, error) {
+	var (
+		is   = finc.NewIntermediateSchema()
+		info = p.Volume.Article.Articleinfo
+		pub  = p.Publicationinfo
+	)
+	is.SourceID = SourceID
+	is.MegaCollections = []string{Collection}
+	is.Format = Format
+	is.Genre = Genre
+	is.RefType = DefaultRefType
+	is.JournalTitle = strings.TrimSpace(p.Title.Text)
+	is.ShortTitle = strings.TrimSpace(p.Titleabbrev.Text)
+	is.ArticleTitle = strings.TrimSpace(p.Volume.Article.Title.Text)
+
+	amsid := strings.TrimSpace(info.Amsid.Text)
+	if amsid == "" {
+		return is, span.Skip{Reason: fmt.Sprintf("NO_ID %s", is.ArticleTitle)}
+	}
+	is.RecordID = amsid
+	is.ID = fmt.Sprintf("ai-%s-%s", SourceID, base64.RawURLEncoding.EncodeToString([]byte(amsid)))
+	is.URL = []string{fmt.Sprintf("http://ieeexplore.ieee.org/stamp/stamp.jsp?arnumber=%s", amsid)}
+	if doi := strings.TrimSpace(info.Articledoi.Text); doi != "" {
+		is.DOI = doi
+		is.URL = append(is.URL, fmt.Sprintf("http://doi.org/%s", is.DOI))
+	}
+
+	if strings.HasPrefix(is.ArticleTitle, "[") {
+		return is, span.Skip{Reason: fmt.Sprintf("EXTRA_CONTENT %s %s", is.ID, is.ArticleTitle)}
+	}
+
+	date, err := p.Date()
+	if err != nil {
+		return is, span.Skip{Reason: fmt.Sprintf("NO_DATE %s", is.ID)}
+	}
+	is.Date = date
+	is.RawDate = date.Format("2006-01-02")
+
+	switch p.publicationType() {
+	case typeConference:
+		is.Genre = "proceeding"
+		is.RefType = "CPAPER"
+		is.BookTitle = is.JournalTitle
+		is.Event = p.Event()
+	case typeStandard:
+		is.Genre = "document"
+		is.RefType = "STAND"
+		if is.ArticleTitle == "" {
+			is.ArticleTitle = is.JournalTitle
+		}
+		is.Series = strings.TrimSpace(p.Standardsfamilytitle.Text)
+		if number := strings.TrimSpace(pub.Stdnumber.Text); number != "" {
+			is.ArticleNumber = fmt.Sprintf("IEEE Std %s", number)
+		}
+		for _, sp := range pub.Standardpackageset.StandardPackage {
+			if v := strings.TrimSpace(sp.Text); v != "" {
+				is.Packages = append(is.Packages, v)
+			}
+		}
+	case typeBook:
+		is.Format = "ElectronicBookPart"
+		is.Genre = "bookitem"
+		is.RefType = "CHAP"
+		is.BookTitle = is.JournalTitle
+	}
+
+	for _, issn := range pub.ISSN {
+		switch strings.ToLower(issn.Mediatype) {
+		case "paper":
+			is.ISSN = append(is.ISSN, strings.TrimSpace(issn.Text))
+		case "online":
+			is.EISSN = append(is.EISSN, strings.TrimSpace(issn.Text))
+		}
+	}
+	for _, isbn := range pub.ISBN {
+		switch strings.ToLower(isbn.Mediatype) {
+		case "online", "electronic":
+			is.EISBN = append(is.EISBN, strings.TrimSpace(isbn.Text))
+		default:
+			is.ISBN = append(is.ISBN, strings.TrimSpace(isbn.Text))
+		}
+	}
+
+	is.Abstract = p.Abstract()
+	is.Authors = p.Authors()
+	is.Subjects = p.Subjects()
+	for _, topic := range info.Articlejournaltopicset.Articlejournaltopic {
+		if v := strings.TrimSpace(topic.Text); v != "" {
+			is.Headings = append(is.Headings, v)
+		}
+	}
+
+	is.Volume = strings.TrimSpace(p.Volume.Volumeinfo.Volumenum.Text)
+	is.Issue = strings.TrimSpace(info.Issuenum.Text)
+	if is.Issue == "" {
+		is.Issue = strings.TrimSpace(p.Volume.Volumeinfo.Issue.Issuenum.Text)
+	}
+	is.StartPage = info.Artpagenums.Startpage
+	is.EndPage = info.Artpagenums.Endpage
+	if is.StartPage != "" || is.EndPage != "" {
+		is.Pages = fmt.Sprintf("%s-%s", is.StartPage, is.EndPage)
+	}
+	if n, err := strconv.Atoi(info.Numpages.Text); err == nil && n > 0 {
+		is.PageCount = strconv.Itoa(n)
+	} else if s, err := strconv.Atoi(is.StartPage); err == nil {
+		if e, err := strconv.Atoi(is.EndPage); err == nil && e >= s {
+			is.PageCount = strconv.Itoa(e - s + 1)
+		}
+	}
+
+	is.Publishers = []string{"IEEE"}
+	if v := strings.TrimSpace(pub.Publisher.Publishername.Text); v != "" {
+		is.Publishers = []string{v}
+	}
+
+	for _, v := range []string{pub.Publicationtype.Text, pub.Publicationsubtype.Text} {
+		if v = strings.TrimSpace(v); v != "" {
+			is.Packages = append(is.Packages, v)
+		}
+	}
+	for _, pm := range pub.Packagememberset.Packagemember {
+		if v := strings.TrimSpace(pm.Text); v != "" {
+			is.Packages = append(is.Packages, v)
+		}
+	}
+
+	// https://supportcenter.ieee.org/app/answers/detail/a_id/1900/~/how-is-the-oapa-different-from-a-cc-by-license%3F
+	license := strings.TrimSpace(info.Articlelicense.Text)
+	if license == "CCBY" || info.Articleopenaccess.Text == "T" {
+		is.OpenAccess = true
+	}
+	if uri := strings.TrimSpace(info.ArticleLicenseURI.Text); uri != "" {
+		is.License = []string{uri}
+	}
+
+	// refs #12966, article title is too short for DetectLang3.
+	if len(is.Abstract) < 100 {
+		is.Languages = []string{"eng"}
+	} else if lang, err := span.DetectLang3(is.Abstract); err == nil {
+		is.Languages = []string{lang}
+	}
+	return is, nil
+}
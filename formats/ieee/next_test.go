@@ -0,0 +1,95 @@
+package ieee
+
+import (
+	"encoding/xml"
+	"io"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/miku/span"
+	"github.com/miku/span/formats/finc"
+)
+
+// loadFixture converts all publications in the ieee-next fixture.
+func loadFixture(t *testing.T) (result []*finc.IntermediateSchema, skipped []string) {
+	f, err := os.Open("../../fixtures/ieee-next.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	dec := xml.NewDecoder(f)
+	for {
+		token, err := dec.Token()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		se, ok := token.(xml.StartElement)
+		if !ok || se.Name.Local != "publication" {
+			continue
+		}
+		var p XPublication
+		if err := dec.DecodeElement(&p, &se); err != nil {
+			t.Fatal(err)
+		}
+		is, err := p.ToIntermediateSchema()
+		if skip, ok := err.(span.Skip); ok {
+			skipped = append(skipped, span.SkipReason(skip))
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		result = append(result, is)
+	}
+}
+
+func TestXPublication(t *testing.T) {
+	docs, skipped := loadFixture(t)
+	if len(docs) != 3 || !reflect.DeepEqual(skipped, []string{"EXTRA_CONTENT"}) {
+		t.Fatalf("got %d records, skipped %v", len(docs), skipped)
+	}
+	var cases = []struct {
+		id, genre, refType, date string
+		subjects                 []string
+	}{
+		{"ai-89-NzgzNTA4Nw", "article", "EJOUR", "2017-01-26",
+			[]string{"HVDC transmission", "Voltage control", "power grids", "droop control"}},
+		{"ai-89-ODMyNDM1OQ", "proceeding", "CPAPER", "2017-12-05", nil},
+		{"ai-89-NzM5NDkwMQ", "document", "STAND", "2015-02-01", []string{"Networking"}},
+	}
+	for i, c := range cases {
+		is := docs[i]
+		if is.ID != c.id || is.Genre != c.genre || is.RefType != c.refType || is.RawDate != c.date {
+			t.Errorf("%d: got %s %s %s %s, want %s %s %s %s", i,
+				is.ID, is.Genre, is.RefType, is.RawDate, c.id, c.genre, c.refType, c.date)
+		}
+		if !reflect.DeepEqual(is.Subjects, c.subjects) {
+			t.Errorf("%d: got subjects %v, want %v", i, is.Subjects, c.subjects)
+		}
+	}
+	if a := docs[0].Authors[0]; a.ORCID != "0000-0002-2201-7327" || len(a.Affiliations) != 1 {
+		t.Errorf("got author %+v", a)
+	}
+	if docs[0].PageCount != "8" || docs[0].ShortTitle != "IET Gener. Transm. Distrib." {
+		t.Errorf("got page count %q, short title %q", docs[0].PageCount, docs[0].ShortTitle)
+	}
+	if !docs[1].OpenAccess || docs[1].Authors[1].Role != finc.RoleEditor {
+		t.Errorf("got open access %v, role %q", docs[1].OpenAccess, docs[1].Authors[1].Role)
+	}
+	event := &finc.Event{
+		Name:      "2017 IEEE International Conference on Robotics and Biomimetics (ROBIO)",
+		Place:     "Macau, Macao",
+		StartDate: "2017-12-05",
+		EndDate:   "2017-12-08",
+	}
+	if !reflect.DeepEqual(docs[1].Event, event) || docs[0].Event != nil {
+		t.Errorf("got event %+v, want %+v", docs[1].Event, event)
+	}
+	if docs[2].ArticleNumber != "IEEE Std 802.1Q-2014/Cor 1-2015" {
+		t.Errorf("got article number %q", docs[2].ArticleNumber)
+	}
+}
@@ -12,6 +12,7 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	"github.com/miku/span"
+	"github.com/miku/span/formats"
 	"github.com/miku/span/formats/finc"
 )
 
@@ -23,6 +24,17 @@ const (
 	DefaultRefType = "EJOUR"
 )
 
+func init() {
+	formats.Register(formats.Format{
+		Name:        "ieee",
+		Shape:       formats.XML,
+		New:         func() interface{} { return new(Publication) },
+		SourceID:    SourceID,
+		Collection:  Collection,
+		Description: "IEEE IDAMS Exchange V2.0.0",
+	})
+}
+
 var (
 	ErrNoDate       = errors.New("no date found")
 	ErrNoIdentifier = errors.New("missing identifier")
@@ -126,6 +138,8 @@ type Publication struct {
 						Surname     string `xml:"surname"`
 						Affiliation string `xml:"affiliation"`
 						Firstname   string `xml:"firstname"`
+						Authortype  string `xml:"authortype"`
+						Orcid       string `xml:"orcid"`
 					} `xml:"author"`
 				} `xml:"authorgroup"`
 				Date []struct {
@@ -254,7 +268,16 @@ func (p Publication) Date() (time.Time, error) {
 func (p Publication) Authors() []finc.Author {
 	var authors []finc.Author
 	for _, author := range p.Volume.Article.Articleinfo.Authorgroup.Author {
-		authors = append(authors, finc.Author{FirstName: author.Firstname, LastName: author.Surname})
+		a := finc.Author{
+			FirstName: author.Firstname,
+			LastName:  author.Surname,
+			Role:      finc.ParseRole(author.Authortype),
+			ORCID:     finc.NormalizeORCID(author.Orcid),
+		}
+		if author.Affiliation != "" {
+			a.Affiliations = []finc.Affiliation{{Name: strings.TrimSpace(author.Affiliation)}}
+		}
+		authors = append(authors, a)
 	}
 	return authors
 }
@@ -272,6 +295,28 @@ func (p Publication) PageCount() string {
 	return fmt.Sprintf("%d", end-start+1)
 }
 
+// Event returns the conference, or nil, if there is no conference title.
+func (p Publication) Event() *finc.Event {
+	cg := p.Publicationinfo.Confgroup
+	if strings.TrimSpace(cg.ConfTitle) == "" {
+		return nil
+	}
+	event := &finc.Event{
+		Name:    strings.TrimSpace(cg.ConfTitle),
+		Acronym: strings.TrimSpace(p.Publicationinfo.Acronym),
+		Place:   eventPlace(cg.Conflocation, cg.Confcountry),
+	}
+	for _, cd := range cg.Confdate {
+		switch cd.Confdatetype {
+		case "Start":
+			event.StartDate = eventDate(cd.Year, cd.Month, cd.Day)
+		case "End":
+			event.EndDate = eventDate(cd.Year, cd.Month, cd.Day)
+		}
+	}
+	return event
+}
+
 // ToIntermediateSchema does a type conversion only.
 func (p Publication) ToIntermediateSchema() (*finc.IntermediateSchema, error) {
 	is := finc.NewIntermediateSchema()
@@ -297,6 +342,7 @@ func (p Publication) ToIntermediateSchema() (*finc.IntermediateSchema, error) {
 	is.RawDate = date.Format("2006-01-02")
 
 	is.Authors = p.Authors()
+	is.Event = p.Event()
 
 	is.URL = []string{}
 
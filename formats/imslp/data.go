@@ -5,12 +5,23 @@ import (
 	"fmt"
 
 	"github.com/beevik/etree"
+	"github.com/miku/span/formats"
 	"github.com/miku/span/formats/finc"
 )
 
 // SourceIdentifier of IMSLP.
 const SourceIdentifier = "15"
 
+func init() {
+	formats.Register(formats.Format{
+		Name:        "imslp",
+		Shape:       formats.Text,
+		New:         func() interface{} { return new(Data) },
+		SourceID:    SourceIdentifier,
+		Description: "IMSLP, single XML document",
+	})
+}
+
 // Data is just the raw bytes.
 type Data []byte
 
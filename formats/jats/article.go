@@ -4,6 +4,7 @@ import (
 	"encoding/xml"
 	"errors"
 	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -66,10 +67,119 @@ type PubDate struct {
 	}
 }
 
+// Aff is an affiliation, either inline in a contrib element or referenced by
+// id from a contrib xref.
+type Aff struct {
+	ID    string `xml:"id,attr"`
+	Value string `xml:",innerxml"`
+	Label struct {
+		XMLName xml.Name `xml:"label"`
+		Value   string   `xml:",chardata"`
+	}
+	Institution   []string `xml:"institution"`
+	WrappedInst   []string `xml:"institution-wrap>institution"`
+	InstitutionID []struct {
+		Type  string `xml:"institution-id-type,attr"`
+		Value string `xml:",chardata"`
+	} `xml:"institution-wrap>institution-id"`
+}
+
+// Conference is a conference element in article-meta.
+type Conference struct {
+	Date struct {
+		Value string `xml:",chardata"`
+		ISO   string `xml:"iso-8601-date,attr"`
+	} `xml:"conf-date"`
+	Name    string `xml:"conf-name"`
+	Acronym string `xml:"conf-acronym"`
+	Number  string `xml:"conf-num"`
+	Loc     struct {
+		Value string `xml:",innerxml"`
+	} `xml:"conf-loc"`
+}
+
+// RelatedArticle links to another article, e.g. a retraction notice to the
+// retracted article.
+type RelatedArticle struct {
+	Type        string `xml:"related-article-type,attr"`
+	ExtLinkType string `xml:"ext-link-type,attr"`
+	Href        string `xml:"href,attr"`
+	Value       string `xml:",innerxml"`
+}
+
+// relatedArticleTypes maps JATS related-article types to relation types.
+// Other types are kept as is.
+var relatedArticleTypes = map[string]string{
+	"retracted-article":  finc.RelationRetracts,
+	"partial-retraction": finc.RelationRetracts,
+	"retraction-forward": finc.RelationIsRetractedBy,
+	"corrected-article":  finc.RelationCorrects,
+	"correction-forward": finc.RelationIsCorrectedBy,
+	"addended-article":   finc.RelationUpdates,
+	"addendum":           finc.RelationIsUpdatedBy,
+	"updated-article":    finc.RelationUpdates,
+	"preprint":           finc.RelationHasPreprint,
+}
+
+// Relation returns the relation to the related article. The target is the
+// link, if any, with DOI as default type, and the text as title.
+func (ra RelatedArticle) Relation() finc.Relation {
+	r := finc.Relation{
+		Type:  strings.ToLower(strings.TrimSpace(ra.Type)),
+		ID:    strings.TrimSpace(ra.Href),
+		Title: strings.Join(strings.Fields(sanitize.HTML(ra.Value)), " "),
+	}
+	if v, ok := relatedArticleTypes[r.Type]; ok {
+		r.Type = v
+	}
+	if r.ID != "" {
+		r.IDType = strings.ToLower(ra.ExtLinkType)
+		if doi := doiPattern.FindString(r.ID); doi != "" && (r.IDType == "" || r.IDType == "doi") {
+			r.ID, r.IDType = doi, "doi"
+		}
+	}
+	return r
+}
+
+// Relations returns relations to related articles in the article metadata.
+func (article *Article) Relations() (relations []finc.Relation) {
+	for _, ra := range article.Front.Article.RelatedArticles {
+		if r := ra.Relation(); r.Type != "" && (r.ID != "" || r.Title != "") {
+			relations = append(relations, r)
+		}
+	}
+	return relations
+}
+
+// isoDatePattern matches dates with year, month and day precision.
+var isoDatePattern = regexp.MustCompile(`^[0-9]{4}(-[0-9]{2}){0,2}$`)
+
+// Event returns the conference or nil. The conference date is free text, it
+// is only used, if it is an ISO date or has an iso-8601-date attribute.
+func (c *Conference) Event() *finc.Event {
+	event := &finc.Event{
+		Name:    strings.Join(strings.Fields(c.Name), " "),
+		Acronym: strings.TrimSpace(c.Acronym),
+		Number:  strings.TrimSpace(c.Number),
+		Place:   strings.Join(strings.Fields(sanitize.HTML(c.Loc.Value)), " "),
+	}
+	for _, v := range []string{c.Date.ISO, c.Date.Value} {
+		if v = strings.TrimSpace(v); isoDatePattern.MatchString(v) {
+			event.StartDate = v
+			break
+		}
+	}
+	if event.IsZero() {
+		return nil
+	}
+	return event
+}
+
 // Article mirrors a JATS article element.
 type Article struct {
 	XMLName xml.Name `xml:"article"`
 	Type    string   `xml:"article-type,attr"`
+	Lang    string   `xml:"lang,attr"`
 	Front   struct {
 		XMLName xml.Name `xml:"front"`
 		Journal struct {
@@ -126,10 +236,20 @@ type Article struct {
 			}
 			ContribGroup struct {
 				XMLName xml.Name `xml:"contrib-group"`
+				Aff     []Aff    `xml:"aff"`
 				Contrib []struct {
 					Type      string `xml:"contrib-type,attr"`
 					XLinkType string `xml:"xlink.type,attr"`
-					Name      struct {
+					ContribID []struct {
+						Type  string `xml:"contrib-id-type,attr"`
+						Value string `xml:",chardata"`
+					} `xml:"contrib-id"`
+					Aff  []Aff `xml:"aff"`
+					XRef []struct {
+						RefType string `xml:"ref-type,attr"`
+						RID     string `xml:"rid,attr"`
+					} `xml:"xref"`
+					Name struct {
 						XMLName xml.Name `xml:"name"`
 						Style   string   `xml:"name-style"`
 						Surname struct {
@@ -171,8 +291,11 @@ type Article struct {
 					} `xml:"subject"`
 				} `xml:"subj-group"`
 			} `xml:"article-categories"`
-			PubDates []PubDate `xml:"pub-date"`
-			Volume   struct {
+			Aff             []Aff            `xml:"aff"`
+			PubDates        []PubDate        `xml:"pub-date"`
+			Conference      *Conference      `xml:"conference"`
+			RelatedArticles []RelatedArticle `xml:"related-article"`
+			Volume          struct {
 				XMLName xml.Name `xml:"volume"`
 				Value   string   `xml:",chardata"`
 			}
@@ -285,18 +408,72 @@ func (article *Article) Identifiers() (Identifiers, error) {
 	return Identifiers{}, errNotImplemented
 }
 
-// Authors returns the authors as slice.
+// Affiliation returns the name and ROR of an affiliation.
+func (aff Aff) Affiliation() finc.Affiliation {
+	var affiliation finc.Affiliation
+	if names := append(aff.Institution, aff.WrappedInst...); len(names) > 0 {
+		affiliation.Name = strings.Join(names, ", ")
+	} else {
+		s := strings.TrimSpace(sanitize.HTML(aff.Value))
+		s = strings.TrimPrefix(s, strings.TrimSpace(aff.Label.Value))
+		affiliation.Name = strings.Join(strings.Fields(s), " ")
+	}
+	for _, id := range aff.InstitutionID {
+		if strings.EqualFold(id.Type, "ror") {
+			affiliation.ROR = finc.NormalizeROR(id.Value)
+		}
+	}
+	return affiliation
+}
+
+// Authors returns authors, editors and translators with identifiers and
+// affiliations. Affiliations are inline or referenced by an xref.
 // TODO(miku): get rid of cross-format dependency.
 func (article *Article) Authors() []finc.Author {
 	var authors []finc.Author
 	group := article.Front.Article.ContribGroup
+	affs := make(map[string]Aff)
+	for _, aff := range append(article.Front.Article.Aff, group.Aff...) {
+		if aff.ID != "" {
+			affs[aff.ID] = aff
+		}
+	}
 	for _, contrib := range group.Contrib {
-		if contrib.Type != "author" {
+		role := finc.ParseRole(contrib.Type)
+		if role != "" && role != finc.RoleEditor && role != finc.RoleTranslator {
+			continue
+		}
+		author := finc.Author{
+			LastName:  strings.TrimSpace(contrib.Name.Surname.Value),
+			FirstName: strings.TrimSpace(contrib.Name.GivenNames.Value),
+			Role:      role,
+		}
+		if author.LastName == "" {
+			author.LastName = strings.TrimSpace(contrib.StringName.Surname.Value)
+			author.FirstName = strings.TrimSpace(contrib.StringName.GivenNames.Value)
+		}
+		if author.LastName == "" && author.FirstName == "" {
 			continue
 		}
-		authors = append(authors, finc.Author{
-			LastName:  contrib.Name.Surname.Value,
-			FirstName: contrib.Name.GivenNames.Value})
+		for _, id := range contrib.ContribID {
+			switch strings.ToLower(id.Type) {
+			case "orcid":
+				author.ORCID = finc.NormalizeORCID(id.Value)
+			case "gnd":
+				author.GND = finc.NormalizeGND(id.Value)
+			case "isni":
+				author.ISNI = finc.NormalizeISNI(id.Value)
+			}
+		}
+		for _, aff := range contrib.Aff {
+			author.Affiliations = append(author.Affiliations, aff.Affiliation())
+		}
+		for _, xref := range contrib.XRef {
+			if aff, ok := affs[xref.RID]; ok && xref.RefType == "aff" {
+				author.Affiliations = append(author.Affiliations, aff.Affiliation())
+			}
+		}
+		authors = append(authors, author)
 	}
 	return authors
 }
@@ -484,6 +661,10 @@ func (article *Article) ToIntermediateSchema() (*finc.IntermediateSchema, error)
 	output.Abstract = strings.TrimSpace(string(article.Front.Article.Abstract.Value))
 	output.ArticleTitle = article.CombinedTitle()
 	output.Authors = article.Authors()
+	if article.Front.Article.Conference != nil {
+		output.Event = article.Front.Article.Conference.Event()
+	}
+	output.Relations = article.Relations()
 	output.Fulltext = strings.TrimSpace(article.Body.Section.Value)
 	if output.Abstract == "" && output.Fulltext != "" {
 		output.Abstract = clipString(output.Fulltext, 200)
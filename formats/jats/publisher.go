@@ -0,0 +1,246 @@
+package jats
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/miku/span"
+	"github.com/miku/span/assetutil"
+	"github.com/miku/span/container"
+	"github.com/miku/span/formats"
+	"github.com/miku/span/formats/finc"
+	"golang.org/x/text/language"
+)
+
+// doiPattern finds a DOI in a link.
+var doiPattern = regexp.MustCompile(`10\.[0-9]+\/\S+`)
+
+func init() {
+	formats.Register(formats.Format{
+		Name:        "jats",
+		Shape:       formats.XML,
+		New:         func() interface{} { return new(Publication) },
+		Description: "JATS or NLM articles, requires a publisher profile (-c)",
+		Configure:   configure,
+	})
+}
+
+// Publisher describes the differences between JATS publishers, so a new
+// publisher only needs a profile, not a new package. The profile is a JSON
+// file or the name of a built-in profile (assets/jats), e.g. degruyter:
+//
+//     {
+//       "source_id": "50",
+//       "collection": "DeGruyter SSH",
+//       "identifiers": ["doi"],
+//       "date_types": ["ppub", "epub"],
+//       "url_template": "http://dx.doi.org/{{ .DOI }}",
+//       "id_from_url": true
+//     }
+//
+// Identifiers are article-id types, tried in order to find a record id; the
+// special type self-uri refers to the self-uri link. The URL template is a
+// text/template, which gets the DOI, RecordID, SelfURI and IDs, a map from
+// article-id type to value, e.g. {{ index .IDs "publisher-id" }}.
+type Publisher struct {
+	SourceID   string `json:"source_id"`
+	Collection string `json:"collection"`
+	Format     string `json:"format"`
+	Genre      string `json:"genre"`
+	RefType    string `json:"reftype"`
+	// Identifiers lists article-id types in order of preference.
+	Identifiers []string `json:"identifiers"`
+	// DateTypes lists pub-date types in order of preference, the first date
+	// is used, if none matches.
+	DateTypes   []string `json:"date_types"`
+	URLTemplate string   `json:"url_template"`
+	// IDFromURL derives the finc id from the URL instead of the record id.
+	IDFromURL bool `json:"id_from_url"`
+	// EISSNTypes lists issn types, that denote an electronic ISSN.
+	EISSNTypes []string `json:"eissn_types"`
+
+	template *template.Template
+}
+
+// LoadPublisher reads a publisher profile from a file or, if there is no such
+// file, loads the built-in profile of that name.
+func LoadPublisher(name string) (*Publisher, error) {
+	b, err := ioutil.ReadFile(name)
+	if os.IsNotExist(err) && !strings.ContainsAny(name, "/.") {
+		if builtin, aerr := assetutil.Load(fmt.Sprintf("assets/jats/%s.json", name)); aerr == nil {
+			b, err = builtin, nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	p := &Publisher{
+		Format:      "ElectronicArticle",
+		Genre:       "article",
+		RefType:     "EJOUR",
+		Identifiers: []string{"doi"},
+		URLTemplate: "https://doi.org/{{ .DOI }}",
+	}
+	if err := json.Unmarshal(b, p); err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	if p.SourceID == "" {
+		return nil, fmt.Errorf("%s: publisher profile requires a source_id", name)
+	}
+	if p.template, err = template.New("url").Parse(p.URLTemplate); err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	return p, nil
+}
+
+// configure returns a copy of the jats format using a publisher profile,
+// which is passed to each record by the factory.
+func configure(name string) (formats.Format, error) {
+	f, _ := formats.Lookup("jats")
+	p, err := LoadPublisher(name)
+	if err != nil {
+		return f, err
+	}
+	f.SourceID = p.SourceID
+	f.Collection = p.Collection
+	f.New = func() interface{} { return &Publication{publisher: p} }
+	return f, nil
+}
+
+// Publication is an article converted according to a publisher profile.
+type Publication struct {
+	XMLName xml.Name `xml:"article"`
+	Article
+
+	publisher *Publisher
+}
+
+// locator is passed to the URL template.
+type locator struct {
+	DOI      string
+	RecordID string
+	SelfURI  string
+	IDs      map[string]string
+}
+
+// ids returns all article ids by type, with the first value for each type.
+func (article *Article) ids() map[string]string {
+	ids := make(map[string]string)
+	for _, id := range article.Front.Article.ID {
+		value := strings.TrimSpace(id.Value)
+		if _, ok := ids[id.Type]; !ok && value != "" {
+			ids[id.Type] = value
+		}
+	}
+	if uri := strings.TrimSpace(article.Front.Article.SelfURI.Value); uri != "" {
+		ids["self-uri"] = uri
+	}
+	return ids
+}
+
+// DateByType returns the date of the first pub-date type found in the list
+// of types, or the first pub-date, if none matches.
+func (article *Article) DateByType(types []string) (t time.Time) {
+	dates := article.Front.Article.PubDates
+	for _, typ := range types {
+		for _, pd := range dates {
+			if pd.Type == typ {
+				return article.parsePubDate(pd)
+			}
+		}
+	}
+	if len(dates) > 0 {
+		return article.parsePubDate(dates[0])
+	}
+	return
+}
+
+// ToIntermediateSchema converts an article according to the publisher profile.
+func (pub *Publication) ToIntermediateSchema() (*finc.IntermediateSchema, error) {
+	p := pub.publisher
+	if p == nil {
+		return nil, fmt.Errorf("jats format requires a publisher profile")
+	}
+	output, err := pub.Article.ToIntermediateSchema()
+	if err != nil {
+		return output, err
+	}
+	ids := pub.ids()
+	for _, typ := range p.Identifiers {
+		if v, ok := ids[typ]; ok {
+			output.RecordID = v
+			break
+		}
+	}
+	output.DOI = ids["doi"]
+	if output.DOI == "" {
+		output.DOI = doiPattern.FindString(ids["self-uri"])
+	}
+	if output.RecordID == "" {
+		return output, span.Skip{Reason: fmt.Sprintf("NO_RECORD_ID %s", strings.Join(p.Identifiers, ", "))}
+	}
+	var buf bytes.Buffer
+	err = p.template.Execute(&buf, locator{
+		DOI:      output.DOI,
+		RecordID: output.RecordID,
+		SelfURI:  ids["self-uri"],
+		IDs:      ids,
+	})
+	if err != nil {
+		return output, err
+	}
+	url := strings.TrimSpace(buf.String())
+	if url != "" {
+		output.URL = []string{url}
+	}
+	key := output.RecordID
+	if p.IDFromURL {
+		key = url
+	}
+	output.ID = fmt.Sprintf("ai-%s-%s", p.SourceID, base64.RawURLEncoding.EncodeToString([]byte(key)))
+	if len(output.ID) > span.KeyLengthLimit {
+		return output, span.Skip{Reason: fmt.Sprintf("ID_TOO_LONG %s", output.ID)}
+	}
+	output.SourceID = p.SourceID
+	output.Format = p.Format
+	output.Genre = p.Genre
+	output.RefType = p.RefType
+	if p.Collection != "" {
+		output.MegaCollections = []string{p.Collection}
+	}
+
+	output.Date = pub.DateByType(p.DateTypes)
+	if output.Date.IsZero() {
+		return output, span.Skip{Reason: fmt.Sprintf("NO_DATE %s", output.ID)}
+	}
+	output.RawDate = output.Date.Format("2006-01-02")
+
+	if len(p.EISSNTypes) > 0 {
+		eissnTypes := container.NewStringSet(p.EISSNTypes...)
+		output.ISSN, output.EISSN = nil, nil
+		for _, issn := range pub.Front.Journal.ISSN {
+			if eissnTypes.Contains(issn.Type) {
+				output.EISSN = append(output.EISSN, issn.Value)
+			} else {
+				output.ISSN = append(output.ISSN, issn.Value)
+			}
+		}
+	}
+	if pub.Lang != "" {
+		if base, err := language.ParseBase(pub.Lang); err == nil {
+			languages := container.NewStringSet(output.Languages...)
+			languages.Add(base.ISO3())
+			output.Languages = languages.SortedValues()
+		}
+	}
+	return output, nil
+}
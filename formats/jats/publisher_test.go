@@ -0,0 +1,186 @@
+package jats
+
+import (
+	"encoding/xml"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/miku/span/formats"
+	"github.com/miku/span/formats/finc"
+)
+
+func TestPublication(t *testing.T) {
+	b, err := ioutil.ReadFile("../../fixtures/jats.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, err := ioutil.TempDir("", "span-jats-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	custom := filepath.Join(dir, "custom.json")
+	err = ioutil.WriteFile(custom, []byte(`{
+		"source_id": "123",
+		"collection": "Example",
+		"identifiers": ["pii", "publisher-id", "doi"],
+		"date_types": ["epub", "ppub"],
+		"url_template": "https://example.com/{{ index .IDs \"publisher-id\" }}",
+		"eissn_types": ["epub"]
+	}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cases = []struct {
+		profile  string
+		id       string
+		recordID string
+		url      []string
+		issn     []string
+		eissn    []string
+	}{
+		{
+			profile:  "degruyter",
+			id:       "ai-50-aHR0cDovL2R4LmRvaS5vcmcvMTAuMTQzMTUveHh4eC0xOTY0LTA3MDE",
+			recordID: "10.14315/xxxx-1964-0701",
+			url:      []string{"http://dx.doi.org/10.14315/xxxx-1964-0701"},
+			issn:     []string{"2198-0470"},
+		},
+		{
+			profile:  custom,
+			id:       "ai-123-eHh4eC0xOTY0LTA3MDE",
+			recordID: "xxxx-1964-0701",
+			url:      []string{"https://example.com/xxxx-1964-0701"},
+			eissn:    []string{"2198-0470"},
+		},
+	}
+	for _, c := range cases {
+		f, err := configure(c.profile)
+		if err != nil {
+			t.Fatalf("%s: %v", c.profile, err)
+		}
+		pub := f.New().(*Publication)
+		if err := xml.Unmarshal(b, pub); err != nil {
+			t.Fatal(err)
+		}
+		is, err := pub.ToIntermediateSchema()
+		if err != nil {
+			t.Fatalf("%s: %v", c.profile, err)
+		}
+		if is.ID != c.id || is.RecordID != c.recordID {
+			t.Errorf("%s: got %s %s, want %s %s", c.profile, is.ID, is.RecordID, c.id, c.recordID)
+		}
+		if is.DOI != "10.14315/xxxx-1964-0701" {
+			t.Errorf("%s: got DOI %s", c.profile, is.DOI)
+		}
+		if !reflect.DeepEqual(is.URL, c.url) {
+			t.Errorf("%s: got URL %v, want %v", c.profile, is.URL, c.url)
+		}
+		if !reflect.DeepEqual(is.ISSN, c.issn) || !reflect.DeepEqual(is.EISSN, c.eissn) {
+			t.Errorf("%s: got %v %v, want %v %v", c.profile, is.ISSN, is.EISSN, c.issn, c.eissn)
+		}
+		if is.RawDate != "1961-02-01" {
+			t.Errorf("%s: got date %s", c.profile, is.RawDate)
+		}
+		if len(is.Authors) != 1 || is.Authors[0].LastName != "Schweixxxx" {
+			t.Errorf("%s: got authors %v", c.profile, is.Authors)
+		}
+	}
+}
+
+func TestLoadPublisher(t *testing.T) {
+	if _, err := LoadPublisher("no-such-publisher"); err == nil {
+		t.Error("expected error for unknown profile")
+	}
+	f, _ := formats.Lookup("jats")
+	if _, err := f.New().(*Publication).ToIntermediateSchema(); err == nil {
+		t.Error("expected error for unconfigured format")
+	}
+}
+
+func TestAuthors(t *testing.T) {
+	doc := `<article><front><article-meta>
+	<contrib-group>
+	  <contrib contrib-type="author">
+	    <contrib-id contrib-id-type="orcid">https://orcid.org/0000-0002-1825-0097</contrib-id>
+	    <name><surname>Carberry</surname><given-names>Josiah</given-names></name>
+	    <xref ref-type="aff" rid="aff1"/>
+	  </contrib>
+	  <contrib contrib-type="editor">
+	    <string-name><surname>Doe</surname><given-names>Jane</given-names></string-name>
+	    <aff><institution-wrap><institution-id institution-id-type="ror">https://ror.org/05gq02987</institution-id><institution>Brown University</institution></institution-wrap></aff>
+	  </contrib>
+	  <aff id="aff1"><label>1</label>Department of Psychoceramics, Brown University</aff>
+	</contrib-group></article-meta></front></article>`
+	var article Article
+	if err := xml.Unmarshal([]byte(doc), &article); err != nil {
+		t.Fatal(err)
+	}
+	authors := article.Authors()
+	if len(authors) != 2 {
+		t.Fatalf("got %d authors, want 2", len(authors))
+	}
+	if authors[0].ORCID != "0000-0002-1825-0097" || authors[0].Role != "" {
+		t.Errorf("got %+v", authors[0])
+	}
+	if len(authors[0].Affiliations) != 1 || authors[0].Affiliations[0].Name != "Department of Psychoceramics, Brown University" {
+		t.Errorf("got affiliations %+v", authors[0].Affiliations)
+	}
+	if authors[1].LastName != "Doe" || authors[1].Role != "editor" {
+		t.Errorf("got %+v", authors[1])
+	}
+	if len(authors[1].Affiliations) != 1 || authors[1].Affiliations[0].ROR != "https://ror.org/05gq02987" {
+		t.Errorf("got affiliations %+v", authors[1].Affiliations)
+	}
+}
+
+func TestConference(t *testing.T) {
+	doc := `<article><front><article-meta>
+	<conference>
+	  <conf-date iso-8601-date="2019-06-03">June 3-5, 2019</conf-date>
+	  <conf-name>International Conference on Example Studies</conf-name>
+	  <conf-acronym>ICES</conf-acronym>
+	  <conf-num>12</conf-num>
+	  <conf-loc><city>Leipzig</city>, <country>Germany</country></conf-loc>
+	</conference></article-meta></front></article>`
+	var article Article
+	if err := xml.Unmarshal([]byte(doc), &article); err != nil {
+		t.Fatal(err)
+	}
+	is, err := article.ToIntermediateSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &finc.Event{
+		Name:      "International Conference on Example Studies",
+		Acronym:   "ICES",
+		Number:    "12",
+		Place:     "Leipzig, Germany",
+		StartDate: "2019-06-03",
+	}
+	if !reflect.DeepEqual(is.Event, want) {
+		t.Errorf("got %+v, want %+v", is.Event, want)
+	}
+}
+
+func TestRelations(t *testing.T) {
+	doc := `<article xmlns:xlink="http://www.w3.org/1999/xlink"><front><article-meta>
+	<related-article related-article-type="retracted-article" ext-link-type="doi" xlink:href="https://doi.org/10.1234/example.1">Original article</related-article>
+	<related-article related-article-type="companion" ext-link-type="uri" xlink:href="https://example.com/companion"/>
+	<related-article related-article-type="correction-forward"/>
+	</article-meta></front></article>`
+	var article Article
+	if err := xml.Unmarshal([]byte(doc), &article); err != nil {
+		t.Fatal(err)
+	}
+	want := []finc.Relation{
+		{Type: finc.RelationRetracts, ID: "10.1234/example.1", IDType: "doi", Title: "Original article"},
+		{Type: "companion", ID: "https://example.com/companion", IDType: "uri"},
+	}
+	if got := article.Relations(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
@@ -33,6 +33,7 @@ import (
 	"github.com/kennygrant/sanitize"
 	"github.com/miku/span"
 	"github.com/miku/span/container"
+	"github.com/miku/span/formats"
 	"github.com/miku/span/formats/finc"
 	"github.com/miku/span/formats/jats"
 	"golang.org/x/text/language"
@@ -47,6 +48,17 @@ const (
 	Format = "ElectronicArticle"
 )
 
+func init() {
+	formats.Register(formats.Format{
+		Name:        "jstor",
+		Shape:       formats.XML,
+		New:         func() interface{} { return new(Article) },
+		SourceID:    SourceID,
+		Collection:  SourceName,
+		Description: "JSTOR JATS",
+	})
+}
+
 var (
 	// ArticleTitleBlockPatterns
 	ArticleTitleBlockPatterns = []*regexp.Regexp{
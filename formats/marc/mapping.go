@@ -0,0 +1,304 @@
+package marc
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/miku/span"
+	"github.com/miku/span/assetutil"
+	"github.com/miku/span/formats"
+	"github.com/miku/span/formats/finc"
+)
+
+// yearPattern finds a plausible year in a date string.
+var yearPattern = regexp.MustCompile(`[12][0-9]{3}`)
+
+func init() {
+	formats.Register(formats.Format{
+		Name:        "marc",
+		Shape:       formats.Stream,
+		Records:     recordsUnconfigured,
+		Description: "MARC-XML or MARC21 (ISO 2709), requires a mapping file (-c)",
+		Configure:   configure,
+	})
+}
+
+// Mapping declares, how to map MARC fields to intermediate schema fields. It
+// is read from a JSON file. Fields are addressed by their JSON key in the
+// intermediate schema, values are lists of MARC specs (see Record.Values),
+// which are tried in order. Mapping files are merged with a default mapping
+// (assets/marc/default.json), so a minimal mapping only needs to provide a
+// source id:
+//
+//     {
+//       "source_id": "123",
+//       "collection": "Example OAI",
+//       "fields": {
+//         "rft.jtitle": ["773.t", "490.a"]
+//       },
+//       "patterns": {
+//         "rft.issn": "^[0-9]{4}-[0-9]{3}[0-9X]$"
+//       }
+//     }
+//
+// Values matching an optional pattern for a key are kept, all others dropped.
+type Mapping struct {
+	SourceID   string              `json:"source_id"`
+	Collection string              `json:"collection"`
+	Format     string              `json:"format"`
+	Genre      string              `json:"genre"`
+	RefType    string              `json:"reftype"`
+	RecordID   []string            `json:"record_id"`
+	Date       []string            `json:"date"`
+	Authors    []string            `json:"authors"`
+	Fields     map[string][]string `json:"fields"`
+	Patterns   map[string]string   `json:"patterns"`
+
+	compiled map[string]*regexp.Regexp
+}
+
+// DefaultMapping returns the built-in mapping, which has no source id.
+func DefaultMapping() (*Mapping, error) {
+	b, err := assetutil.Load("assets/marc/default.json")
+	if err != nil {
+		return nil, err
+	}
+	var m Mapping
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	return &m, m.compile()
+}
+
+// LoadMapping reads a mapping file and merges it with the default mapping.
+func LoadMapping(filename string) (*Mapping, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	m, err := DefaultMapping()
+	if err != nil {
+		return nil, err
+	}
+	var custom Mapping
+	if err := json.Unmarshal(b, &custom); err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+	m.merge(custom)
+	if m.SourceID == "" {
+		return nil, fmt.Errorf("%s: mapping requires a source_id", filename)
+	}
+	return m, m.compile()
+}
+
+// merge overrides values with the non-empty values of another mapping.
+func (m *Mapping) merge(o Mapping) {
+	for _, p := range []struct {
+		dst *string
+		src string
+	}{
+		{&m.SourceID, o.SourceID},
+		{&m.Collection, o.Collection},
+		{&m.Format, o.Format},
+		{&m.Genre, o.Genre},
+		{&m.RefType, o.RefType},
+	} {
+		if p.src != "" {
+			*p.dst = p.src
+		}
+	}
+	for _, p := range []struct {
+		dst *[]string
+		src []string
+	}{
+		{&m.RecordID, o.RecordID},
+		{&m.Date, o.Date},
+		{&m.Authors, o.Authors},
+	} {
+		if len(p.src) > 0 {
+			*p.dst = p.src
+		}
+	}
+	if m.Fields == nil {
+		m.Fields = make(map[string][]string)
+	}
+	for k, v := range o.Fields {
+		m.Fields[k] = v
+	}
+	if m.Patterns == nil {
+		m.Patterns = make(map[string]string)
+	}
+	for k, v := range o.Patterns {
+		m.Patterns[k] = v
+	}
+}
+
+// compile checks field names and specs and compiles patterns.
+func (m *Mapping) compile() error {
+	var probe finc.IntermediateSchema
+	var record Record
+	lists := [][]string{m.RecordID, m.Date, m.Authors}
+	for key, specs := range m.Fields {
+		if err := probe.SetField(key); err != nil {
+			return err
+		}
+		lists = append(lists, specs)
+	}
+	for _, specs := range lists {
+		for _, spec := range specs {
+			if _, err := record.Values(spec); err != nil {
+				return err
+			}
+		}
+	}
+	m.compiled = make(map[string]*regexp.Regexp)
+	for key, pattern := range m.Patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("pattern for %s: %v", key, err)
+		}
+		m.compiled[key] = re
+	}
+	return nil
+}
+
+// values collects values for a list of specs, dropping empty values and
+// values that do not match the pattern registered for key.
+func (m *Mapping) values(r Record, key string, specs []string) (result []string) {
+	for _, spec := range specs {
+		values, err := r.Values(spec)
+		if err != nil {
+			continue
+		}
+		for _, v := range values {
+			v = strings.TrimSpace(v)
+			if v == "" {
+				continue
+			}
+			if re, ok := m.compiled[key]; ok && !re.MatchString(v) {
+				continue
+			}
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// ToIntermediateSchema converts a MARC record according to this mapping.
+func (m *Mapping) ToIntermediateSchema(r Record) (*finc.IntermediateSchema, error) {
+	output := finc.NewIntermediateSchema()
+	if r.Header.Status == "deleted" {
+		return output, span.Skip{Reason: fmt.Sprintf("DELETED %s", r.Header.Identifier.Text)}
+	}
+	output.SourceID = m.SourceID
+	output.Format = m.Format
+	output.Genre = m.Genre
+	output.RefType = m.RefType
+	if m.Collection != "" {
+		output.MegaCollections = []string{m.Collection}
+	}
+
+	ids := m.values(r, "record_id", m.RecordID)
+	if len(ids) > 0 {
+		output.RecordID = ids[0]
+	} else {
+		output.RecordID = r.Header.Identifier.Text
+	}
+	if output.RecordID == "" {
+		return output, span.Skip{Reason: "NO_RECORD_ID"}
+	}
+	output.ID = fmt.Sprintf("ai-%s-%s", m.SourceID, base64.RawURLEncoding.EncodeToString([]byte(output.RecordID)))
+	if len(output.ID) > span.KeyLengthLimit {
+		return output, span.Skip{Reason: fmt.Sprintf("ID_TOO_LONG %s", output.ID)}
+	}
+
+	for key, specs := range m.Fields {
+		if err := output.SetField(key, m.values(r, key, specs)...); err != nil {
+			return output, err
+		}
+	}
+	for _, name := range m.values(r, "authors", m.Authors) {
+		output.Authors = append(output.Authors, finc.Author{Name: strings.TrimRight(name, " ,")})
+	}
+
+	for _, v := range m.values(r, "date", m.Date) {
+		if t, err := time.Parse("2006-01-02", v); err == nil {
+			output.RawDate, output.Date = v, t
+			break
+		}
+		year := yearPattern.FindString(v)
+		if year == "" {
+			continue
+		}
+		output.RawDate = fmt.Sprintf("%s-01-01", year)
+		output.Date, _ = time.Parse("2006-01-02", output.RawDate)
+		break
+	}
+	if output.Date.IsZero() {
+		return output, span.Skip{Reason: fmt.Sprintf("NO_DATE %s", output.ID)}
+	}
+	if output.ArticleTitle == "" && output.BookTitle == "" {
+		return output, span.Skip{Reason: fmt.Sprintf("NO_ATITLE %s", output.ID)}
+	}
+	return output, nil
+}
+
+// mappedRecord is a MARC record along with the mapping to convert it.
+type mappedRecord struct {
+	mapping *Mapping
+	record  Record
+}
+
+// ToIntermediateSchema converts the record with the mapping.
+func (r mappedRecord) ToIntermediateSchema() (*finc.IntermediateSchema, error) {
+	return r.mapping.ToIntermediateSchema(r.record)
+}
+
+// recordReader reads MARC records for conversion with a mapping.
+type recordReader struct {
+	mapping *Mapping
+	reader  *Reader
+	err     error
+}
+
+// Read returns the next record, ready for conversion, and its offset.
+func (r *recordReader) Read() (interface{}, int64, error) {
+	if r.err != nil {
+		return nil, 0, r.err
+	}
+	record, err := r.reader.Read()
+	if err != nil {
+		return nil, r.reader.Offset, err
+	}
+	return mappedRecord{mapping: r.mapping, record: record}, r.reader.Offset, nil
+}
+
+// Records returns a reader over the MARC records in r, which are converted
+// with this mapping.
+func (m *Mapping) Records(r io.Reader) formats.RecordReader {
+	return &recordReader{mapping: m, reader: NewReader(r)}
+}
+
+// recordsUnconfigured is used, if no mapping file is given.
+func recordsUnconfigured(r io.Reader) formats.RecordReader {
+	return &recordReader{err: fmt.Errorf("marc format requires a mapping file")}
+}
+
+// configure returns a copy of the marc format using the given mapping file.
+func configure(filename string) (formats.Format, error) {
+	f, _ := formats.Lookup("marc")
+	m, err := LoadMapping(filename)
+	if err != nil {
+		return f, err
+	}
+	f.Records = m.Records
+	f.SourceID = m.SourceID
+	f.Collection = m.Collection
+	return f, nil
+}
@@ -0,0 +1,203 @@
+package marc
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+const (
+	// Delimiters used in ISO 2709 records.
+	subfieldDelimiter = 0x1F
+	fieldTerminator   = 0x1E
+	recordTerminator  = 0x1D
+
+	leaderLength         = 24
+	directoryEntryLength = 12
+)
+
+// oaiNamespace is used to tell apart OAI-PMH wrapper records from MARC records.
+const oaiNamespace = "http://www.openarchives.org/OAI/2.0/"
+
+// ErrInvalidRecord signals a malformed ISO 2709 record.
+var ErrInvalidRecord = errors.New("invalid ISO 2709 record")
+
+// Reader reads MARC records from MARC-XML (plain collections or wrapped in
+// OAI-PMH responses) or from binary MARC21 (ISO 2709). The serialization is
+// detected by looking at the first non-whitespace byte of the input.
+type Reader struct {
+	br     *bufio.Reader
+	dec    *xml.Decoder
+	header Header
+	// hasHeader is set, while an OAI header waits for its record.
+	hasHeader bool
+	sniff     bool
+	isXML     bool
+	// Offset is the byte offset, at which the most recently read record
+	// starts, for XML the offset of its record or OAI header element.
+	Offset int64
+	offset int64
+}
+
+// NewReader creates a new reader.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{br: bufio.NewReader(r), sniff: true}
+}
+
+// IsXML reports, whether the input has been detected as XML. Only meaningful
+// after the first call to Read.
+func (r *Reader) IsXML() bool {
+	return r.isXML
+}
+
+// detect peeks at the input to decide whether we read XML or ISO 2709.
+func (r *Reader) detect() error {
+	r.sniff = false
+	for {
+		b, err := r.br.Peek(1)
+		if err != nil {
+			return err
+		}
+		switch b[0] {
+		case ' ', '\t', '\r', '\n':
+			if _, err := r.br.ReadByte(); err != nil {
+				return err
+			}
+			r.offset++
+			continue
+		case '<':
+			r.isXML = true
+			r.dec = xml.NewDecoder(r.br)
+			r.dec.Strict = false
+		}
+		return nil
+	}
+}
+
+// Read returns the next record or io.EOF, if there are no more records.
+func (r *Reader) Read() (Record, error) {
+	if r.sniff {
+		if err := r.detect(); err != nil {
+			return Record{}, err
+		}
+	}
+	if r.isXML {
+		return r.readXML()
+	}
+	return r.readBinary()
+}
+
+// readXML finds the next MARC-XML record. OAI-PMH headers are remembered and
+// attached to the following MARC record. Deleted OAI records, which carry
+// no metadata, are returned with the header only.
+func (r *Reader) readXML() (Record, error) {
+	for {
+		offset := r.dec.InputOffset()
+		token, err := r.dec.Token()
+		if err != nil {
+			return Record{}, err
+		}
+		se, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch {
+		case se.Name.Local == "header" && se.Name.Space == oaiNamespace:
+			var header Header
+			if err := r.dec.DecodeElement(&header, &se); err != nil {
+				return Record{}, err
+			}
+			r.header, r.hasHeader = header, true
+			r.Offset = offset
+			if header.Status == "deleted" {
+				r.header, r.hasHeader = Header{}, false
+				return Record{Header: header}, nil
+			}
+		case se.Name.Local == "record" && se.Name.Space != oaiNamespace:
+			var record Record
+			if err := r.dec.DecodeElement(&record.Metadata.Record, &se); err != nil {
+				return Record{}, err
+			}
+			if !r.hasHeader {
+				r.Offset = offset
+			}
+			record.Header, r.header, r.hasHeader = r.header, Header{}, false
+			return record, nil
+		}
+	}
+}
+
+// readBinary reads a single ISO 2709 record.
+func (r *Reader) readBinary() (Record, error) {
+	var record Record
+	r.Offset = r.offset
+	leader := make([]byte, leaderLength)
+	n, err := io.ReadFull(r.br, leader)
+	r.offset += int64(n)
+	if err == io.EOF {
+		return record, io.EOF
+	}
+	if err != nil {
+		return record, ErrInvalidRecord
+	}
+	length, err := strconv.Atoi(string(leader[0:5]))
+	if err != nil || length < leaderLength {
+		return record, fmt.Errorf("%v: record length %q at offset %d", ErrInvalidRecord, leader[0:5], r.Offset)
+	}
+	base, err := strconv.Atoi(string(leader[12:17]))
+	if err != nil || base < leaderLength || base > length {
+		return record, fmt.Errorf("%v: base address %q at offset %d", ErrInvalidRecord, leader[12:17], r.Offset)
+	}
+	rest := make([]byte, length-leaderLength)
+	n, err = io.ReadFull(r.br, rest)
+	r.offset += int64(n)
+	if err != nil {
+		return record, fmt.Errorf("%v: short record at offset %d", ErrInvalidRecord, r.Offset)
+	}
+	record.Metadata.Record.Leader.Text = string(leader)
+
+	directory := rest[:base-leaderLength]
+	if i := bytes.IndexByte(directory, fieldTerminator); i >= 0 {
+		directory = directory[:i]
+	}
+	data := rest[base-leaderLength:]
+
+	for i := 0; i+directoryEntryLength <= len(directory); i += directoryEntryLength {
+		entry := directory[i : i+directoryEntryLength]
+		tag := string(entry[0:3])
+		flen, err := strconv.Atoi(string(entry[3:7]))
+		if err != nil {
+			return record, fmt.Errorf("%v: field length in %s at offset %d", ErrInvalidRecord, tag, r.Offset)
+		}
+		start, err := strconv.Atoi(string(entry[7:12]))
+		if err != nil {
+			return record, fmt.Errorf("%v: field start in %s at offset %d", ErrInvalidRecord, tag, r.Offset)
+		}
+		if start+flen > len(data) {
+			return record, fmt.Errorf("%v: field %s out of bounds at offset %d", ErrInvalidRecord, tag, r.Offset)
+		}
+		value := bytes.TrimRight(data[start:start+flen], string([]byte{fieldTerminator, recordTerminator}))
+		if tag < "010" {
+			record.Metadata.Record.Controlfield = append(record.Metadata.Record.Controlfield,
+				Controlfield{Tag: tag, Text: string(value)})
+			continue
+		}
+		df := Datafield{Tag: tag}
+		if len(value) >= 2 {
+			df.Ind1, df.Ind2 = string(value[0]), string(value[1])
+			value = value[2:]
+		}
+		for _, sf := range bytes.Split(value, []byte{subfieldDelimiter}) {
+			if len(sf) == 0 {
+				continue
+			}
+			df.Subfield = append(df.Subfield, Subfield{Code: string(sf[0]), Text: string(sf[1:])})
+		}
+		record.Metadata.Record.Datafield = append(record.Metadata.Record.Datafield, df)
+	}
+	return record, nil
+}
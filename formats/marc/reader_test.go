@@ -0,0 +1,170 @@
+package marc
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+)
+
+// iso2709 serializes a minimal binary MARC record with a given control
+// number and a list of data fields, given as tag and raw field content.
+func iso2709(controlNumber string, fields ...[2]string) []byte {
+	var directory, data bytes.Buffer
+	add := func(tag, value string) {
+		value = value + "\x1e"
+		directory.WriteString(fmt.Sprintf("%s%04d%05d", tag, len(value), data.Len()))
+		data.WriteString(value)
+	}
+	add("001", controlNumber)
+	for _, f := range fields {
+		add(f[0], f[1])
+	}
+	directory.WriteByte(0x1e)
+	base := 24 + directory.Len()
+	length := base + data.Len() + 1
+	leader := fmt.Sprintf("%05dnam a22%05d u 4500", length, base)
+	return []byte(leader + directory.String() + data.String() + "\x1d")
+}
+
+func TestReaderBinary(t *testing.T) {
+	var buf bytes.Buffer
+	buf.Write(iso2709("1", [2]string{"245", "10\x1faHello\x1fbWorld"}))
+	buf.Write(iso2709("2", [2]string{"700", "1 \x1faDoe, Jane"}, [2]string{"700", "1 \x1faRoe, Richard"}))
+
+	r := NewReader(&buf)
+	var records []Record
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Read: %v", err)
+		}
+		records = append(records, record)
+	}
+	if r.IsXML() {
+		t.Errorf("IsXML: got true, want false")
+	}
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want 2", len(records))
+	}
+	var tests = []struct {
+		record int
+		spec   string
+		result []string
+	}{
+		{0, "001", []string{"1"}},
+		{0, "245.a", []string{"Hello"}},
+		{0, "245.b", []string{"World"}},
+		{0, "245", []string{"Hello World"}},
+		{0, "LDR/06", []string{"a"}},
+		{0, "LDR/06-07", []string{"am"}},
+		{1, "001", []string{"2"}},
+		{1, "700.a", []string{"Doe, Jane", "Roe, Richard"}},
+		{1, "245.a", nil},
+	}
+	for _, c := range tests {
+		result, err := records[c.record].Values(c.spec)
+		if err != nil {
+			t.Errorf("Values(%s): %v", c.spec, err)
+		}
+		if !reflect.DeepEqual(result, c.result) {
+			t.Errorf("Values(%s): got %v, want %v", c.spec, result, c.result)
+		}
+	}
+}
+
+func TestReaderBinaryInvalid(t *testing.T) {
+	r := NewReader(bytes.NewReader([]byte("00100nam a2200000 u 4500")))
+	if _, err := r.Read(); err == nil {
+		t.Errorf("Read: expected error on truncated record")
+	}
+}
+
+func TestReaderXML(t *testing.T) {
+	f, err := os.Open("../../fixtures/marc.oai.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	r := NewReader(f)
+	record, err := r.Read()
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if !r.IsXML() {
+		t.Errorf("IsXML: got false, want true")
+	}
+	if record.Header.Identifier.Text != "oai:example.com:1" {
+		t.Errorf("got %q, want oai:example.com:1", record.Header.Identifier.Text)
+	}
+	if v := record.MustGetFirstDataField("245.a"); v != "An example article" {
+		t.Errorf("got %q, want An example article", v)
+	}
+	if v, _ := record.Values("008/07-10"); !reflect.DeepEqual(v, []string{"2017"}) {
+		t.Errorf("got %v, want [2017]", v)
+	}
+	deleted, err := r.Read()
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if deleted.Header.Status != "deleted" {
+		t.Errorf("got status %q, want deleted", deleted.Header.Status)
+	}
+	b, err := ioutil.ReadFile("../../fixtures/marc.oai.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := int64(bytes.Index(b, []byte(`<header status="deleted"`))); r.Offset != want {
+		t.Errorf("Offset: got %d, want %d", r.Offset, want)
+	}
+	if _, err := r.Read(); err != io.EOF {
+		t.Errorf("got %v, want io.EOF", err)
+	}
+}
+
+func TestMapping(t *testing.T) {
+	m, err := LoadMapping("../../fixtures/marc.mapping.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open("../../fixtures/marc.oai.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	record, err := NewReader(f).Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	is, err := m.ToIntermediateSchema(record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if is.SourceID != "999" {
+		t.Errorf("SourceID: got %q, want 999", is.SourceID)
+	}
+	if is.ArticleTitle != "An example article" || is.ArticleSubtitle != "with a subtitle" {
+		t.Errorf("titles: got %q, %q", is.ArticleTitle, is.ArticleSubtitle)
+	}
+	if is.JournalTitle != "Journal of Examples" {
+		t.Errorf("JournalTitle: got %q", is.JournalTitle)
+	}
+	if is.DOI != "10.1234/example.1" {
+		t.Errorf("DOI: got %q", is.DOI)
+	}
+	if is.RawDate != "2017-01-01" {
+		t.Errorf("RawDate: got %q, want 2017-01-01", is.RawDate)
+	}
+	if !reflect.DeepEqual(is.Languages, []string{"ger"}) {
+		t.Errorf("Languages: got %v, want [ger]", is.Languages)
+	}
+	if len(is.Authors) != 1 || is.Authors[0].Name != "Doe, Jane" {
+		t.Errorf("Authors: got %v", is.Authors)
+	}
+}
@@ -3,53 +3,72 @@ package marc
 import (
 	"encoding/xml"
 	"fmt"
+	"strconv"
 	"strings"
 )
 
-// Record for MARC-XML data.
+// Header is an OAI-PMH record header.
+type Header struct {
+	Text       string `xml:",chardata"`
+	Status     string `xml:"status,attr"`
+	Identifier struct {
+		Text string `xml:",chardata"` // oai:gesis.izsoz.de:docume...
+	} `xml:"identifier"`
+	Datestamp struct {
+		Text string `xml:",chardata"` // 2012-08-29T21:40:31Z, 201...
+	} `xml:"datestamp"`
+	SetSpec []struct {
+		Text string `xml:",chardata"` // com_community_10100, com_...
+	} `xml:"setSpec"`
+}
+
+// Leader of a MARC record.
+type Leader struct {
+	Text string `xml:",chardata"` // 00000nam a2200000 u 4500,...
+}
+
+// Controlfield is a MARC control field, e.g. 001 or 008.
+type Controlfield struct {
+	Text string `xml:",chardata"` // 20080514135900.0, cr|||||...
+	Tag  string `xml:"tag,attr"`
+}
+
+// Subfield of a MARC data field.
+type Subfield struct {
+	Text string `xml:",chardata"` // b, http://www.ssoar.info/...
+	Code string `xml:"code,attr"`
+}
+
+// Datafield is a MARC data field with indicators and subfields.
+type Datafield struct {
+	Text     string     `xml:",chardata"`
+	Ind2     string     `xml:"ind2,attr"`
+	Ind1     string     `xml:"ind1,attr"`
+	Tag      string     `xml:"tag,attr"`
+	Subfield []Subfield `xml:"subfield"`
+}
+
+// Slim is a single MARC-XML record, http://www.loc.gov/standards/marcxml/.
+type Slim struct {
+	Text           string         `xml:",chardata"`
+	Xmlns          string         `xml:"xmlns,attr"`
+	Doc            string         `xml:"doc,attr"`
+	Xalan          string         `xml:"xalan,attr"`
+	Xsi            string         `xml:"xsi,attr"`
+	SchemaLocation string         `xml:"schemaLocation,attr"`
+	Leader         Leader         `xml:"leader"`
+	Controlfield   []Controlfield `xml:"controlfield"`
+	Datafield      []Datafield    `xml:"datafield"`
+}
+
+// Record for MARC-XML data, wrapped in an OAI-PMH record.
 type Record struct {
-	XMLName xml.Name `xml:"Record"`
-	Text    string   `xml:",chardata"`
-	Header  struct {
-		Text       string `xml:",chardata"`
-		Status     string `xml:"status,attr"`
-		Identifier struct {
-			Text string `xml:",chardata"` // oai:gesis.izsoz.de:docume...
-		} `xml:"identifier"`
-		Datestamp struct {
-			Text string `xml:",chardata"` // 2012-08-29T21:40:31Z, 201...
-		} `xml:"datestamp"`
-		SetSpec []struct {
-			Text string `xml:",chardata"` // com_community_10100, com_...
-		} `xml:"setSpec"`
-	} `xml:"header"`
+	XMLName  xml.Name `xml:"Record"`
+	Text     string   `xml:",chardata"`
+	Header   Header   `xml:"header"`
 	Metadata struct {
 		Text   string `xml:",chardata"`
-		Record struct {
-			Text           string `xml:",chardata"`
-			Xmlns          string `xml:"xmlns,attr"`
-			Doc            string `xml:"doc,attr"`
-			Xalan          string `xml:"xalan,attr"`
-			Xsi            string `xml:"xsi,attr"`
-			SchemaLocation string `xml:"schemaLocation,attr"`
-			Leader         struct {
-				Text string `xml:",chardata"` // 00000nam a2200000 u 4500,...
-			} `xml:"leader"`
-			Controlfield []struct {
-				Text string `xml:",chardata"` // 20080514135900.0, cr|||||...
-				Tag  string `xml:"tag,attr"`
-			} `xml:"controlfield"`
-			Datafield []struct {
-				Text     string `xml:",chardata"`
-				Ind2     string `xml:"ind2,attr"`
-				Ind1     string `xml:"ind1,attr"`
-				Tag      string `xml:"tag,attr"`
-				Subfield []struct {
-					Text string `xml:",chardata"` // b, http://www.ssoar.info/...
-					Code string `xml:"code,attr"`
-				} `xml:"subfield"`
-			} `xml:"datafield"`
-		} `xml:"record"`
+		Record Slim   `xml:"record"`
 	} `xml:"metadata"`
 	About struct {
 		Text string `xml:",chardata"`
@@ -117,3 +136,82 @@ func (r Record) GetDataFields(spec string) (result []string, err error) {
 	}
 	return result, nil
 }
+
+// Values returns the values for a given spec. A spec can address the leader
+// ("LDR"), a control field ("001"), a position or range in the leader or a
+// control field ("LDR/07", "008/07-10"), a data field subfield ("245.a") or
+// all subfields of a data field, joined by space ("245").
+func (r Record) Values(spec string) (result []string, err error) {
+	var tag, rng string
+	if i := strings.Index(spec, "/"); i > 0 {
+		tag, rng = spec[:i], spec[i+1:]
+	} else {
+		tag = spec
+	}
+	if strings.Contains(tag, ".") {
+		if rng != "" {
+			return nil, fmt.Errorf("ranges are only supported for leader and control fields: %s", spec)
+		}
+		return r.GetDataFields(tag)
+	}
+	var values []string
+	switch {
+	case tag == "LDR":
+		values = append(values, r.Metadata.Record.Leader.Text)
+	case tag < "010":
+		for _, f := range r.Metadata.Record.Controlfield {
+			if f.Tag == tag {
+				values = append(values, f.Text)
+			}
+		}
+	default:
+		for _, f := range r.Metadata.Record.Datafield {
+			if f.Tag != tag {
+				continue
+			}
+			var parts []string
+			for _, sf := range f.Subfield {
+				parts = append(parts, strings.TrimSpace(sf.Text))
+			}
+			values = append(values, strings.Join(parts, " "))
+		}
+		if rng != "" {
+			return nil, fmt.Errorf("ranges are only supported for leader and control fields: %s", spec)
+		}
+	}
+	if rng == "" {
+		return values, nil
+	}
+	begin, end, err := parseRange(rng)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range values {
+		if end >= len(v) {
+			continue
+		}
+		result = append(result, v[begin:end+1])
+	}
+	return result, nil
+}
+
+// parseRange parses a position "07" or a range "07-10" into inclusive bounds.
+func parseRange(s string) (begin, end int, err error) {
+	parts := strings.Split(s, "-")
+	if len(parts) > 2 {
+		return 0, 0, fmt.Errorf("invalid range: %s", s)
+	}
+	if begin, err = strconv.Atoi(parts[0]); err != nil {
+		return 0, 0, fmt.Errorf("invalid range: %s", s)
+	}
+	end = begin
+	if len(parts) == 2 {
+		if end, err = strconv.Atoi(parts[1]); err != nil {
+			return 0, 0, fmt.Errorf("invalid range: %s", s)
+		}
+	}
+	if begin < 0 || end < begin {
+		return 0, 0, fmt.Errorf("invalid range: %s", s)
+	}
+	return begin, end, nil
+}
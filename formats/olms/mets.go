@@ -266,14 +266,14 @@ type MetsRecord struct {
 func (record MetsRecord) ToIntermediateSchema() (*finc.IntermediateSchema, error) {
 	output := finc.NewIntermediateSchema()
 
-	output.SourceID = "12502"
+	output.SourceID = SourceID
 	parts := strings.Split(record.Header.Identifier.Text, ":")
 	if len(parts) != 2 {
 		return output, fmt.Errorf("cannot find identifier: %s", record.Header.Identifier.Text)
 	}
 	output.RecordID = record.Header.Identifier.Text
 	output.ID = fmt.Sprintf("ai-%s-%s", output.SourceID, parts[1])
-	output.MegaCollections = append(output.MegaCollections, "Olms")
+	output.MegaCollections = append(output.MegaCollections, Collection)
 	output.Genre = "article"
 	output.RefType = "EJOUR"
 
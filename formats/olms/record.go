@@ -7,10 +7,36 @@ import (
 	"time"
 
 	"github.com/miku/span"
-
+	"github.com/miku/span/formats"
 	"github.com/miku/span/formats/finc"
 )
 
+const (
+	// SourceID for internal bookkeeping.
+	SourceID = "12502"
+	// Collection for finc.mega_collection.
+	Collection = "Olms"
+)
+
+func init() {
+	formats.Register(formats.Format{
+		Name:        "olms",
+		Shape:       formats.XML,
+		New:         func() interface{} { return new(Record) },
+		SourceID:    SourceID,
+		Collection:  Collection,
+		Description: "Olms, Dublin Core via OAI",
+	})
+	formats.Register(formats.Format{
+		Name:        "olms-mets",
+		Shape:       formats.XML,
+		New:         func() interface{} { return new(MetsRecord) },
+		SourceID:    SourceID,
+		Collection:  Collection,
+		Description: "Olms, METS/MODS via OAI",
+	})
+}
+
 // Record was generated 2018-03-01 19:44:04 by tir on hayiti.
 type Record struct {
 	XMLName xml.Name `xml:"record"`
@@ -73,14 +99,14 @@ type Record struct {
 func (record Record) ToIntermediateSchema() (*finc.IntermediateSchema, error) {
 	output := finc.NewIntermediateSchema()
 
-	output.SourceID = "12502"
+	output.SourceID = SourceID
 	parts := strings.Split(record.Header.Identifier.Text, ":")
 	if len(parts) != 2 {
 		return output, fmt.Errorf("cannot find identifier: %s", record.Header.Identifier.Text)
 	}
 	output.RecordID = record.Header.Identifier.Text
 	output.ID = fmt.Sprintf("ai-%s-%s", output.SourceID, parts[1])
-	output.MegaCollections = append(output.MegaCollections, "Olms")
+	output.MegaCollections = append(output.MegaCollections, Collection)
 	output.Genre = "article"
 	output.RefType = "EJOUR"
 
@@ -0,0 +1,140 @@
+package formats
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"text/template"
+
+	"github.com/miku/span/formats/finc"
+)
+
+// Profile describes a source, independent of its format. A profile is applied
+// to a record after conversion, so two sources sharing a format can be told
+// apart without new code. Example profile:
+//
+//     {
+//       "source_id": "49",
+//       "collection_template": "{{ first .Publishers }} (CrossRef)",
+//       "collection_rename": {"Elsevier BV (CrossRef)": "Elsevier (CrossRef)"},
+//       "defaults": {"languages": ["eng"]},
+//       "overrides": {"ris.type": ["EJOUR"]}
+//     }
+//
+// Defaults and overrides are keyed by intermediate schema JSON keys. Defaults
+// are only set, if the field is empty, overrides always replace the value.
+type Profile struct {
+	// SourceID replaces the source id; the source id part of the record
+	// identifier is adjusted accordingly.
+	SourceID string `json:"source_id"`
+	// Collection replaces the collections of a record.
+	Collection string `json:"collection"`
+	// CollectionTemplate is a text/template evaluated with the record to
+	// yield a collection name. Empty results leave collections untouched.
+	CollectionTemplate string `json:"collection_template"`
+	// CollectionRename maps collection names to new names.
+	CollectionRename map[string]string   `json:"collection_rename"`
+	Defaults         map[string][]string `json:"defaults"`
+	Overrides        map[string][]string `json:"overrides"`
+
+	template *template.Template
+}
+
+// templateFuncs are available in collection templates.
+var templateFuncs = template.FuncMap{
+	"first": func(s []string) string {
+		if len(s) == 0 {
+			return ""
+		}
+		return s[0]
+	},
+	"join": strings.Join,
+}
+
+// LoadProfile reads a profile from a JSON file.
+func LoadProfile(filename string) (*Profile, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var p Profile
+	if err := json.Unmarshal(b, &p); err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+	if err := p.compile(); err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+	return &p, nil
+}
+
+// compile checks field keys and parses the collection template.
+func (p *Profile) compile() error {
+	var probe finc.IntermediateSchema
+	for _, m := range []map[string][]string{p.Defaults, p.Overrides} {
+		for key := range m {
+			if _, err := probe.Field(key); err != nil {
+				return err
+			}
+		}
+	}
+	if p.CollectionTemplate == "" {
+		return nil
+	}
+	t, err := template.New("collection").Funcs(templateFuncs).Parse(p.CollectionTemplate)
+	if err != nil {
+		return err
+	}
+	p.template = t
+	return nil
+}
+
+// Apply adjusts a converted record according to the profile.
+func (p *Profile) Apply(is *finc.IntermediateSchema) error {
+	for key, values := range p.Overrides {
+		if err := is.ClearField(key); err != nil {
+			return err
+		}
+		if err := is.SetField(key, values...); err != nil {
+			return err
+		}
+	}
+	for key, values := range p.Defaults {
+		current, err := is.Field(key)
+		if err != nil {
+			return err
+		}
+		if len(current) > 0 {
+			continue
+		}
+		if err := is.SetField(key, values...); err != nil {
+			return err
+		}
+	}
+	if p.SourceID != "" && p.SourceID != is.SourceID {
+		prefix := fmt.Sprintf("ai-%s-", is.SourceID)
+		if strings.HasPrefix(is.ID, prefix) {
+			is.ID = fmt.Sprintf("ai-%s-%s", p.SourceID, strings.TrimPrefix(is.ID, prefix))
+		}
+		is.SourceID = p.SourceID
+	}
+	if p.Collection != "" {
+		is.MegaCollections = []string{p.Collection}
+	}
+	if p.template != nil {
+		var buf bytes.Buffer
+		if err := p.template.Execute(&buf, is); err != nil {
+			return err
+		}
+		if name := strings.TrimSpace(buf.String()); name != "" {
+			is.MegaCollections = []string{name}
+		}
+	}
+	for i, name := range is.MegaCollections {
+		if renamed, ok := p.CollectionRename[name]; ok {
+			is.MegaCollections[i] = renamed
+		}
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package formats
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/miku/span/formats/finc"
+)
+
+func TestProfileApply(t *testing.T) {
+	p := &Profile{
+		SourceID:           "1000",
+		CollectionTemplate: "{{ first .Publishers }} (Example)",
+		CollectionRename:   map[string]string{"ACME (Example)": "ACME Journals"},
+		Defaults: map[string][]string{
+			"languages": {"eng"},
+			"rft.genre": {"book"},
+		},
+		Overrides: map[string][]string{
+			"ris.type": {"EJOUR"},
+		},
+	}
+	if err := p.compile(); err != nil {
+		t.Fatal(err)
+	}
+	is := finc.NewIntermediateSchema()
+	is.ID = "ai-49-eHl6"
+	is.SourceID = "49"
+	is.Genre = "article"
+	is.RefType = "JOUR"
+	is.Publishers = []string{"ACME"}
+	is.MegaCollections = []string{"ACME (CrossRef)"}
+	if err := p.Apply(is); err != nil {
+		t.Fatal(err)
+	}
+	if is.ID != "ai-1000-eHl6" || is.SourceID != "1000" {
+		t.Errorf("got %s, %s, want ai-1000-eHl6, 1000", is.ID, is.SourceID)
+	}
+	if is.Genre != "article" {
+		t.Errorf("default should not replace existing value, got %s", is.Genre)
+	}
+	if is.RefType != "EJOUR" {
+		t.Errorf("override: got %s, want EJOUR", is.RefType)
+	}
+	if !reflect.DeepEqual(is.Languages, []string{"eng"}) {
+		t.Errorf("default: got %v, want [eng]", is.Languages)
+	}
+	if !reflect.DeepEqual(is.MegaCollections, []string{"ACME Journals"}) {
+		t.Errorf("collection: got %v, want [ACME Journals]", is.MegaCollections)
+	}
+}
+
+func TestProfileInvalidKey(t *testing.T) {
+	p := &Profile{Defaults: map[string][]string{"no.such.key": {"x"}}}
+	if err := p.compile(); err == nil {
+		t.Errorf("expected error for unknown key")
+	}
+}
@@ -0,0 +1,179 @@
+// Package formats keeps a registry of input formats. Each format package
+// registers itself in an init function, so adding a source means adding a
+// single package. Tools like span-import look up formats by name:
+//
+//     import _ "github.com/miku/span/formats/all"
+//
+//     f, ok := formats.Lookup("crossref")
+//     if !ok {
+//         log.Fatal("unknown format")
+//     }
+//     v := f.New()
+//
+// The shape of a format tells a tool, how to read records from the input.
+package formats
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/miku/span/formats/finc"
+)
+
+// Shape describes how records of a format are laid out in the input.
+type Shape int
+
+const (
+	// XML is a stream of XML, containing zero, one or more records,
+	// identified by an XML element.
+	XML Shape = iota
+	// NDJSON is newline delimited JSON, one record per line.
+	NDJSON
+	// Text is a single record of arbitrary shape, read at once. The record
+	// needs to implement encoding.TextUnmarshaler.
+	Text
+	// Archive is a container with multiple files, that needs a custom
+	// conversion, e.g. a tar file.
+	Archive
+	// Stream is a sequence of records in a custom serialization, e.g. binary
+	// MARC, read with a RecordReader. Unlike archives, compressed files and
+	// archives are unpacked and each file or member is read on its own.
+	Stream
+)
+
+// String returns a short name for the shape.
+func (s Shape) String() string {
+	switch s {
+	case XML:
+		return "xml"
+	case NDJSON:
+		return "ndjson"
+	case Text:
+		return "text"
+	case Archive:
+		return "archive"
+	case Stream:
+		return "stream"
+	default:
+		return fmt.Sprintf("shape(%d)", int(s))
+	}
+}
+
+// IntermediateSchemaer wrap a basic conversion method.
+type IntermediateSchemaer interface {
+	ToIntermediateSchema() (*finc.IntermediateSchema, error)
+}
+
+// Factory creates a new, empty record of a format.
+type Factory func() interface{}
+
+// EmitFunc receives a converted record. Records, that could not be converted,
+// are passed along with the error, e.g. a span.Skip; the record may be nil or
+// only partially filled then. If EmitFunc returns an error, the conversion
+// stops.
+type EmitFunc func(is *finc.IntermediateSchema, err error) error
+
+// RecordReader reads the records of a Stream format one by one. Read returns
+// a record, that implements IntermediateSchemaer, along with its byte offset
+// in the input, or io.EOF, if there are no more records.
+type RecordReader interface {
+	Read() (record interface{}, offset int64, err error)
+}
+
+// ArchiveFunc converts a whole archive read from r and calls emit for each
+// converted or failed record.
+type ArchiveFunc func(r io.Reader, emit EmitFunc) error
+
+// Format describes an input format.
+type Format struct {
+	// Name is used to select the format, e.g. with span-import -i.
+	Name string
+	// Shape of the input.
+	Shape Shape
+	// New returns a pointer to a new record, for XML, NDJSON and Text shapes.
+	New Factory
+	// Convert handles the Archive shape.
+	Convert ArchiveFunc
+	// ConvertFiles handles the Archive shape for formats, that need to open
+	// input files by name, e.g. directories, or that combine several files.
+	// Optional, used instead of Convert, if filenames are given.
+	ConvertFiles func(filenames []string, emit EmitFunc) error
+	// Records returns a reader over the records of a single input, for the
+	// Stream shape.
+	Records func(r io.Reader) RecordReader
+	// SourceID is the default source identifier.
+	SourceID string
+	// Collection is the default collection name, if any.
+	Collection string
+	// Description is a short, human readable note.
+	Description string
+	// Configure returns a copy of the format, adjusted by a configuration
+	// file, e.g. a field mapping. Optional.
+	Configure func(filename string) (Format, error)
+}
+
+var (
+	mu       sync.RWMutex
+	registry = make(map[string]Format)
+)
+
+// Register makes a format available by its name. It panics, if the name is
+// empty, if it is registered twice or if the format lacks a factory or
+// conversion function for its shape.
+func Register(f Format) {
+	mu.Lock()
+	defer mu.Unlock()
+	if f.Name == "" {
+		panic("formats: register format without name")
+	}
+	if _, dup := registry[f.Name]; dup {
+		panic("formats: register called twice for format " + f.Name)
+	}
+	switch f.Shape {
+	case Archive:
+		if f.Convert == nil {
+			panic("formats: archive format without conversion function: " + f.Name)
+		}
+	case Stream:
+		if f.Records == nil {
+			panic("formats: stream format without record reader: " + f.Name)
+		}
+	default:
+		if f.New == nil {
+			panic("formats: format without factory: " + f.Name)
+		}
+	}
+	registry[f.Name] = f
+}
+
+// Lookup returns the format registered under a given name.
+func Lookup(name string) (Format, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	f, ok := registry[name]
+	return f, ok
+}
+
+// Names returns the sorted names of all registered formats.
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	var names []string
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// All returns all registered formats, sorted by name.
+func All() []Format {
+	var result []Format
+	for _, name := range Names() {
+		f, _ := Lookup(name)
+		result = append(result, f)
+	}
+	return result
+}
@@ -11,10 +11,29 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	"github.com/miku/span"
+	"github.com/miku/span/formats"
 	"github.com/miku/span/formats/finc"
 	"github.com/miku/span/formats/marc"
 )
 
+const (
+	// SourceID for internal bookkeeping.
+	SourceID = "30"
+	// Collection for finc.mega_collection.
+	Collection = "SSOAR Social Science Open Access Repository"
+)
+
+func init() {
+	formats.Register(formats.Format{
+		Name:        "ssoar",
+		Shape:       formats.XML,
+		New:         func() interface{} { return new(Record) },
+		SourceID:    SourceID,
+		Collection:  Collection,
+		Description: "SSOAR, MARC XML via OAI",
+	})
+}
+
 type Record struct {
 	marc.Record
 }
@@ -198,10 +217,10 @@ func (r Record) ToIntermediateSchema() (*finc.IntermediateSchema, error) {
 	}
 
 	output.RecordID = id
-	output.SourceID = "30"
+	output.SourceID = SourceID
 	output.ID = fmt.Sprintf("ai-%s-%s", output.SourceID, output.RecordID)
 	output.Format = r.FindFormat()
-	output.MegaCollections = []string{"SSOAR Social Science Open Access Repository"}
+	output.MegaCollections = []string{Collection}
 
 	switch output.Format {
 	case "eBook":
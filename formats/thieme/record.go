@@ -10,6 +10,7 @@ import (
 	"github.com/kennygrant/sanitize"
 	"github.com/miku/span"
 	"github.com/miku/span/assetutil"
+	"github.com/miku/span/formats"
 	"github.com/miku/span/formats/finc"
 )
 
@@ -21,6 +22,17 @@ const (
 	DefaultRefType = "EJOUR"
 )
 
+func init() {
+	formats.Register(formats.Format{
+		Name:        "thieme-nlm",
+		Shape:       formats.XML,
+		New:         func() interface{} { return new(Record) },
+		SourceID:    SourceID,
+		Collection:  Collection,
+		Description: "Thieme, NLM style via OAI",
+	})
+}
+
 func leftPad(s string, padStr string, overallLen int) string {
 	padCountInt := 1 + ((overallLen - len(padStr)) / len(padStr))
 	var retStr = strings.Repeat(padStr, padCountInt) + s
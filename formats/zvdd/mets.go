@@ -249,11 +249,11 @@ func (r *MetsRecord) ToIntermediateSchema() (output *finc.IntermediateSchema, er
 
 	}
 
-	output.SourceID = "93"
+	output.SourceID = SourceIdentifier
 	output.ID = fmt.Sprintf("ai-%s-%s", output.SourceID,
 		base64.RawURLEncoding.EncodeToString([]byte(r.Header.Identifier)))
 	output.RecordID = r.Header.Identifier
-	output.MegaCollections = []string{"ZVDD"}
+	output.MegaCollections = []string{Collection}
 	output.Genre = "document"
 	output.RefType = "EJOUR"
 	output.Format = "ElectronicArticle"
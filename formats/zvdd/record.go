@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/miku/span/formats"
 	"github.com/miku/span/formats/finc"
 )
 
@@ -18,6 +19,25 @@ const (
 	Collection       = "ZVDD"
 )
 
+func init() {
+	formats.Register(formats.Format{
+		Name:        "zvdd",
+		Shape:       formats.XML,
+		New:         func() interface{} { return new(DublicCoreRecord) },
+		SourceID:    SourceIdentifier,
+		Collection:  Collection,
+		Description: "ZVDD, Dublin Core via OAI",
+	})
+	formats.Register(formats.Format{
+		Name:        "zvdd-mets",
+		Shape:       formats.XML,
+		New:         func() interface{} { return new(MetsRecord) },
+		SourceID:    SourceIdentifier,
+		Collection:  Collection,
+		Description: "ZVDD, METS/MODS via OAI",
+	})
+}
+
 // DublicCoreRecord is a sketch for highwire XML.
 type DublicCoreRecord struct {
 	XMLName xml.Name `xml:"Record"`
@@ -0,0 +1,242 @@
+package span
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+
+	"github.com/klauspost/compress/zstd"
+	gzip "github.com/klauspost/pgzip"
+)
+
+// Magic numbers of supported compression and archive formats.
+var (
+	magicGzip  = []byte{0x1f, 0x8b}
+	magicBzip2 = []byte("BZh")
+	magicZstd  = []byte{0x28, 0xb5, 0x2f, 0xfd}
+	magicXz    = []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}
+	magicZip   = []byte("PK\x03\x04")
+	magicTar   = []byte("ustar") // At offset 257.
+)
+
+// InputError wraps an error with the name of the file or archive member, the
+// error occured in.
+type InputError struct {
+	Name string
+	Err  error
+}
+
+// Error returns the name of the input and the error.
+func (e *InputError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Name, e.Err)
+}
+
+// Decompress detects gzip, bzip2 and zstd compressed data by its magic bytes
+// and returns a decompressing reader along with the name of the compression.
+// Uncompressed data is passed through, with an empty compression name.
+func Decompress(r io.Reader) (io.Reader, string, error) {
+	br := bufio.NewReader(r)
+	b, err := br.Peek(6)
+	if err != nil && err != io.EOF {
+		return nil, "", err
+	}
+	switch {
+	case bytes.HasPrefix(b, magicGzip):
+		zr, err := gzip.NewReader(br)
+		return zr, "gzip", err
+	case bytes.HasPrefix(b, magicBzip2):
+		return bzip2.NewReader(br), "bzip2", nil
+	case bytes.HasPrefix(b, magicZstd):
+		zr, err := zstd.NewReader(br)
+		if err != nil {
+			return nil, "", err
+		}
+		return zr.IOReadCloser(), "zstd", nil
+	case bytes.HasPrefix(b, magicXz):
+		return nil, "", fmt.Errorf("xz compression is not supported")
+	}
+	return br, "", nil
+}
+
+// WalkInput opens the given files (standard input, if there are none or for
+// a "-") and calls f with each file. Compressed files are decompressed. If
+// unpack is true, tar and zip archives are unpacked and f is called for each
+// regular file in the archive, with a name like "delivery.tar.gz/a/b.xml".
+// Archives may be nested. Errors are wrapped in an InputError with the name
+// of the file or member.
+func WalkInput(filenames []string, unpack bool, f func(name string, r io.Reader) error) error {
+	if len(filenames) == 0 {
+		filenames = []string{"-"}
+	}
+	for _, filename := range filenames {
+		if filename == "-" {
+			if err := walk("-", os.Stdin, unpack, f); err != nil {
+				return err
+			}
+			continue
+		}
+		file, err := os.Open(filename)
+		if err != nil {
+			return err
+		}
+		err = walk(filename, file, unpack, f)
+		file.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// walk decompresses a single input and either unpacks it or hands it to f.
+func walk(name string, r io.Reader, unpack bool, f func(name string, r io.Reader) error) error {
+	dr, _, err := Decompress(r)
+	if err != nil {
+		return &InputError{Name: name, Err: err}
+	}
+	if c, ok := dr.(io.Closer); ok {
+		defer c.Close()
+	}
+	br := bufio.NewReaderSize(dr, 4096)
+	// Readers passed on hide the WriterTo of bufio.Reader, which hands a copy
+	// to the WriterTo of the decompressor, e.g. pgzip, which may report
+	// io.EOF after an earlier Peek.
+	rd := struct{ io.Reader }{br}
+	if unpack {
+		b, err := br.Peek(262)
+		if err != nil && err != io.EOF {
+			return &InputError{Name: name, Err: err}
+		}
+		switch {
+		case len(b) >= 262 && bytes.Equal(b[257:262], magicTar):
+			return walkTar(name, rd, f)
+		case bytes.HasPrefix(b, magicZip):
+			return walkZip(name, r, rd, f)
+		}
+	}
+	if err := f(name, rd); err != nil {
+		if _, ok := err.(*InputError); ok {
+			return err
+		}
+		return &InputError{Name: name, Err: err}
+	}
+	return nil
+}
+
+// walkTar walks regular files of a tar archive.
+func walkTar(name string, r io.Reader, f func(name string, r io.Reader) error) error {
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return &InputError{Name: name, Err: err}
+		}
+		if header.Typeflag != tar.TypeReg && header.Typeflag != tar.TypeRegA {
+			continue
+		}
+		if err := walk(path.Join(name, header.Name), tr, true, f); err != nil {
+			return err
+		}
+	}
+}
+
+// walkZip walks regular files of a zip archive. Zip requires random access,
+// so unless the archive is a plain file, it is copied into a temporary file
+// first.
+func walkZip(name string, orig io.Reader, br io.Reader, f func(name string, r io.Reader) error) error {
+	var (
+		ra   io.ReaderAt
+		size int64
+	)
+	if file, ok := orig.(*os.File); ok && isZipFile(file) {
+		if fi, err := file.Stat(); err == nil && fi.Mode().IsRegular() {
+			ra, size = file, fi.Size()
+		}
+	}
+	if ra == nil {
+		tmp, err := ioutil.TempFile("", "span-zip-")
+		if err != nil {
+			return err
+		}
+		defer os.Remove(tmp.Name())
+		defer tmp.Close()
+		n, err := io.Copy(tmp, br)
+		if err != nil {
+			return &InputError{Name: name, Err: err}
+		}
+		ra, size = tmp, n
+	}
+	zr, err := zip.NewReader(ra, size)
+	if err != nil {
+		return &InputError{Name: name, Err: err}
+	}
+	for _, zf := range zr.File {
+		if !zf.Mode().IsRegular() {
+			continue
+		}
+		rc, err := zf.Open()
+		if err != nil {
+			return &InputError{Name: path.Join(name, zf.Name), Err: err}
+		}
+		err = walk(path.Join(name, zf.Name), rc, true, f)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isZipFile checks for the zip magic number at the start of a file.
+func isZipFile(ra io.ReaderAt) bool {
+	b := make([]byte, len(magicZip))
+	if _, err := ra.ReadAt(b, 0); err != nil {
+		return false
+	}
+	return bytes.Equal(b, magicZip)
+}
+
+// OpenInput returns a single reader over all given files and archive
+// members, decompressed and unpacked as with WalkInput. A newline is inserted
+// between members, that do not end with one, so line oriented formats are
+// not garbled.
+func OpenInput(filenames ...string) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		lw := &lastByteWriter{w: pw}
+		pw.CloseWithError(WalkInput(filenames, true, func(_ string, r io.Reader) error {
+			if _, err := io.Copy(lw, r); err != nil {
+				return err
+			}
+			if lw.last != '\n' && lw.last != 0 {
+				_, err := lw.Write([]byte("\n"))
+				return err
+			}
+			return nil
+		}))
+	}()
+	return pr
+}
+
+// lastByteWriter remembers the last byte written.
+type lastByteWriter struct {
+	w    io.Writer
+	last byte
+}
+
+func (w *lastByteWriter) Write(p []byte) (int, error) {
+	if len(p) > 0 {
+		w.last = p[len(p)-1]
+	}
+	return w.w.Write(p)
+}
@@ -0,0 +1,140 @@
+package span
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+func gzipBytes(t *testing.T, b []byte) []byte {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(b); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestWalkInput(t *testing.T) {
+	dir, err := ioutil.TempDir("", "span-input-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	write := func(name string, b []byte) string {
+		filename := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(filename, b, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return filename
+	}
+
+	// A tar.gz with a plain and a gzipped member.
+	var tbuf bytes.Buffer
+	tw := tar.NewWriter(&tbuf)
+	for _, m := range []struct {
+		name string
+		body []byte
+	}{
+		{"x/a.txt", []byte("a\n")},
+		{"x/b.txt.gz", gzipBytes(t, []byte("b\n"))},
+	} {
+		if err := tw.WriteHeader(&tar.Header{Name: m.name, Mode: 0644, Size: int64(len(m.body)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(m.body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A zip file.
+	var zbuf bytes.Buffer
+	zw := zip.NewWriter(&zbuf)
+	w, err := zw.Create("c.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.WriteString(w, "c") // No trailing newline.
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A zstd file.
+	enc, err := zstd.NewWriter(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zstdBytes := enc.EncodeAll([]byte("d\n"), nil)
+
+	filenames := []string{
+		write("p.txt", []byte("p\n")),
+		write("t.tar.gz", gzipBytes(t, tbuf.Bytes())),
+		write("z.zip", zbuf.Bytes()),
+		write("z.zip.gz", gzipBytes(t, zbuf.Bytes())),
+		write("d.zst", zstdBytes),
+	}
+	var names, contents []string
+	err = WalkInput(filenames, true, func(name string, r io.Reader) error {
+		b, err := ioutil.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, name)
+		names = append(names, rel)
+		contents = append(contents, string(b))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantNames := []string{"p.txt", "t.tar.gz/x/a.txt", "t.tar.gz/x/b.txt.gz", "z.zip/c.txt", "z.zip.gz/c.txt", "d.zst"}
+	if !reflect.DeepEqual(names, wantNames) {
+		t.Errorf("got %v, want %v", names, wantNames)
+	}
+	wantContents := []string{"p\n", "a\n", "b\n", "c", "c", "d\n"}
+	if !reflect.DeepEqual(contents, wantContents) {
+		t.Errorf("got %q, want %q", contents, wantContents)
+	}
+
+	// Errors carry the member name.
+	err = WalkInput(filenames[1:2], true, func(name string, r io.Reader) error {
+		return io.ErrUnexpectedEOF
+	})
+	if e, ok := err.(*InputError); !ok || filepath.Base(e.Name) != "a.txt" {
+		t.Errorf("got %v, want InputError for a.txt", err)
+	}
+
+	// Concatenated input, with newlines between members.
+	rc := OpenInput(filenames...)
+	defer rc.Close()
+	b, err := ioutil.ReadAll(rc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "p\na\nb\nc\nc\nd\n"; string(b) != want {
+		t.Errorf("OpenInput: got %q, want %q", b, want)
+	}
+
+	// A small gzip file, copied at once.
+	rc = OpenInput(write("a.gz", gzipBytes(t, []byte("a\n"))))
+	defer rc.Close()
+	if b, err = ioutil.ReadAll(rc); err != nil || string(b) != "a\n" {
+		t.Errorf("OpenInput: got %q, %v, want %q", b, err, "a\n")
+	}
+}
@@ -9,11 +9,75 @@ import (
 	"io/ioutil"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
+
+	log "github.com/sirupsen/logrus"
 )
 
+// Retrier sends HTTP requests and retries them on network errors, 429 and
+// server errors, with exponential backoff. A Retry-After header in seconds
+// on 429 and 503 responses overrides the next delay.
+type Retrier struct {
+	// MaxRetries is the number of retries for a single request.
+	MaxRetries int
+	// Backoff is the initial delay between retries, doubled after each attempt.
+	Backoff time.Duration
+	// Client is used for HTTP requests, defaults to http.DefaultClient.
+	Client *http.Client
+}
+
+// Do sends the request returned by newRequest, which is called for each
+// attempt, and returns the response body. Client errors, other than 429, are
+// not retried.
+func (r *Retrier) Do(newRequest func() (*http.Request, error)) ([]byte, error) {
+	client := r.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	var lastErr error
+	delay := r.Backoff
+	for attempt := 0; attempt <= r.MaxRetries; attempt++ {
+		if attempt > 0 {
+			log.Printf("[retry] %d/%d in %s: %v", attempt, r.MaxRetries, delay, lastErr)
+			time.Sleep(delay)
+			delay *= 2
+		}
+		req, err := newRequest()
+		if err != nil {
+			return nil, err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		b, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		switch {
+		case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable:
+			if s, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && s > 0 {
+				delay = time.Duration(s) * time.Second
+			}
+			lastErr = fmt.Errorf("request to %s failed with: %s", req.URL, resp.Status)
+		case resp.StatusCode >= 500:
+			lastErr = fmt.Errorf("request to %s failed with: %s", req.URL, resp.Status)
+		case resp.StatusCode >= 400:
+			return nil, fmt.Errorf("request to %s failed with: %s: %s", req.URL, resp.Status, bytes.TrimSpace(b))
+		default:
+			return b, nil
+		}
+	}
+	return nil, lastErr
+}
+
 // LinkReader implements io.Reader for a URL.
 type LinkReader struct {
 	Link string
@@ -5,6 +5,8 @@ import (
 	"encoding/base64"
 	"io"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"strings"
 	"testing"
@@ -31,6 +33,46 @@ func TestLinkReader(t *testing.T) {
 	}
 }
 
+func TestRetrier(t *testing.T) {
+	var tests = []struct {
+		statuses []int // responses in order, 200 after that
+		retries  int
+		requests int
+		err      bool
+	}{
+		{nil, 2, 1, false},
+		{[]int{503, 429, 500}, 3, 4, false},
+		{[]int{503, 429, 500}, 2, 3, true},
+		{[]int{404}, 2, 1, true},
+		{[]int{500, 400}, 2, 2, true},
+	}
+	for _, c := range tests {
+		var requests int
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			if requests <= len(c.statuses) {
+				w.WriteHeader(c.statuses[requests-1])
+				return
+			}
+			io.WriteString(w, "ok")
+		}))
+		r := &Retrier{MaxRetries: c.retries}
+		b, err := r.Do(func() (*http.Request, error) {
+			return http.NewRequest("GET", ts.URL, nil)
+		})
+		ts.Close()
+		if (err != nil) != c.err {
+			t.Errorf("%v: got %v, want error=%v", c.statuses, err, c.err)
+		}
+		if err == nil && string(b) != "ok" {
+			t.Errorf("%v: got %q, want ok", c.statuses, b)
+		}
+		if requests != c.requests {
+			t.Errorf("%v: got %d requests, want %d", c.statuses, requests, c.requests)
+		}
+	}
+}
+
 func TestSavedLink(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping going out to the net")
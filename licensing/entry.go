@@ -38,6 +38,7 @@ var (
 
 	intPattern  = regexp.MustCompile("[0-9]+")
 	issnPattern = regexp.MustCompile(`[0-9]{4,4}-[0-9]{3,3}[0-9xX]`)
+	doiPattern  = regexp.MustCompile(`10[.][0-9]{4,}/[^\s?#]+`)
 )
 
 // dateWithGranularity groups layout and granularity.
@@ -145,6 +146,29 @@ func (entry *Entry) ISSNList() []string {
 	return issns.SortedValues()
 }
 
+// ISBNList returns a list of unique ISBN, normalized to ISBN-13 without
+// hyphens, from print and online identifier, as used for e-books.
+func (entry *Entry) ISBNList() []string {
+	isbns := container.NewStringSet()
+	for _, isbn := range []string{entry.PrintIdentifier, entry.OnlineIdentifier} {
+		if s := NormalizeISBN(isbn); s != "" {
+			isbns.Add(s)
+		}
+	}
+	return isbns.SortedValues()
+}
+
+// DOI returns the lowercase DOI of the title, from title_id, e.g.
+// 10.1007/978-3-658-10838-0, or from the title URL, if there is any.
+func (entry *Entry) DOI() string {
+	for _, s := range []string{entry.TitleID, entry.TitleURL} {
+		if doi := doiPattern.FindString(s); doi != "" {
+			return strings.ToLower(doi)
+		}
+	}
+	return ""
+}
+
 // Covers is a generic method to determine, whether a given date, volume or
 // issue is covered by this entry. It takes into account moving walls. If
 // values are not defined, we assume they are not constrained. It is an error,
@@ -199,15 +223,7 @@ func (entry *Entry) begin() time.Time {
 
 // beginGranularity returns the begin date with a given granularity.
 func (entry *Entry) beginGranularity(g DateGranularity) time.Time {
-	t := entry.begin()
-	switch g {
-	case GRANULARITY_YEAR:
-		return time.Date(t.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
-	case GRANULARITY_MONTH:
-		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
-	default:
-		return t
-	}
+	return TruncateGranularity(entry.begin(), g)
 }
 
 // end parses right boundary of license interval, returns a date far in the future
@@ -227,7 +243,12 @@ func (entry *Entry) end() time.Time {
 
 // endGranularity returns the end date with a given granularity.
 func (entry *Entry) endGranularity(g DateGranularity) time.Time {
-	t := entry.end()
+	return TruncateGranularity(entry.end(), g)
+}
+
+// TruncateGranularity returns the first day of the year or month of a date,
+// for year or month granularity.
+func TruncateGranularity(t time.Time, g DateGranularity) time.Time {
 	switch g {
 	case GRANULARITY_YEAR:
 		return time.Date(t.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
@@ -278,6 +299,62 @@ func NormalizeSerialNumber(s string) string {
 	return s
 }
 
+// NormalizeISBN returns a valid ISBN-10 or ISBN-13 as ISBN-13 without hyphens,
+// e.g. 0-306-40615-2 becomes 9780306406157. Invalid values yield an empty
+// string.
+func NormalizeISBN(s string) string {
+	var b []byte
+	for _, c := range strings.ToUpper(s) {
+		switch {
+		case c >= '0' && c <= '9', c == 'X':
+			b = append(b, byte(c))
+		case c == '-', c == ' ':
+		default:
+			return ""
+		}
+	}
+	switch len(b) {
+	case 10:
+		sum := 0
+		for i, c := range b {
+			v := int(c - '0')
+			if c == 'X' {
+				if i != 9 {
+					return ""
+				}
+				v = 10
+			}
+			sum += (10 - i) * v
+		}
+		if sum%11 != 0 {
+			return ""
+		}
+		b = append([]byte("978"), b[:9]...)
+		b = append(b, isbn13CheckDigit(b))
+		return string(b)
+	case 13:
+		if strings.Contains(string(b), "X") || isbn13CheckDigit(b[:12]) != b[12] {
+			return ""
+		}
+		return string(b)
+	}
+	return ""
+}
+
+// isbn13CheckDigit returns the check digit for the first twelve digits of an
+// ISBN-13.
+func isbn13CheckDigit(b []byte) byte {
+	sum := 0
+	for i, c := range b[:12] {
+		if i%2 == 0 {
+			sum += int(c - '0')
+		} else {
+			sum += 3 * int(c-'0')
+		}
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
 // FindSerialNumbers returns ISSN in standard form in a given string.
 func FindSerialNumbers(s string) []string {
 	return issnPattern.FindAllString(s, -1)
@@ -301,6 +378,12 @@ func parseWithGranularity(s string) (t time.Time, g DateGranularity, err error)
 	return t, g, ErrInvalidDate
 }
 
+// ParseWithGranularity parses a date, like a KBART issue date or a record
+// date, e.g. 2010, 2010-04 or 2010-04-01, and returns its granularity.
+func ParseWithGranularity(s string) (time.Time, DateGranularity, error) {
+	return parseWithGranularity(s)
+}
+
 // getGranularity returns the granularity for given date layout, if nothing
 // matches assume the finest granularity.
 func getGranularity(layout string) DateGranularity {
@@ -332,3 +332,42 @@ func BenchmarkCovers(b *testing.B) {
 // BenchmarkCovers/partial-4 	 5000000	       362 ns/op
 // PASS
 // ok  	github.com/miku/span/licensing	8.267s
+
+func TestNormalizeISBN(t *testing.T) {
+	var cases = []struct {
+		s      string
+		result string
+	}{
+		{"0-306-40615-2", "9780306406157"},
+		{"978-0-306-40615-7", "9780306406157"},
+		{"9780306406157", "9780306406157"},
+		{"0-8044-2957-x", "9780804429573"},
+		{"0-306-40615-3", ""},
+		{"978-0-306-40615-8", ""},
+		{"1234-5678", ""},
+		{"ISBN 0306406152", ""},
+		{"", ""},
+	}
+	for _, c := range cases {
+		if result := NormalizeISBN(c.s); result != c.result {
+			t.Errorf("NormalizeISBN(%q): got %q, want %q", c.s, result, c.result)
+		}
+	}
+}
+
+func TestEntryDOI(t *testing.T) {
+	var cases = []struct {
+		entry  Entry
+		result string
+	}{
+		{Entry{TitleID: "10.1007/978-3-658-10838-0"}, "10.1007/978-3-658-10838-0"},
+		{Entry{TitleID: "22540", TitleURL: "http://link.springer.com/10.1007/978-3-658-15644-2"}, "10.1007/978-3-658-15644-2"},
+		{Entry{TitleID: "10.1002/(ISSN)1097-0258"}, "10.1002/(issn)1097-0258"},
+		{Entry{TitleID: "22540", TitleURL: "http://www.karger.com/dne"}, ""},
+	}
+	for _, c := range cases {
+		if result := c.entry.DOI(); result != c.result {
+			t.Errorf("DOI: got %q, want %q", result, c.result)
+		}
+	}
+}
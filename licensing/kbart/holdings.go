@@ -44,46 +44,55 @@ func (h *Holdings) ReadFrom(r io.Reader) (int64, error) {
 	return int64(wc.Count()), nil
 }
 
-// SerialNumberMap creates a map from ISSN to associated licensing entries.
-// This is here for performance mostly, so we can access relevant licensing
-// entry by ISSN.  XXX: Do not replicate entries, just index into them.
-func (h *Holdings) SerialNumberMap() map[string][]licensing.Entry {
+// index groups entries by the keys a function returns for each entry, each
+// entry appears at most once per key.
+func (h *Holdings) index(keys func(licensing.Entry) []string) map[string][]licensing.Entry {
 	cache := make(map[string]map[licensing.Entry]bool)
 	for _, e := range *h {
-		for _, issn := range e.ISSNList() {
-			if cache[issn] == nil {
-				cache[issn] = make(map[licensing.Entry]bool)
+		for _, key := range keys(e) {
+			if cache[key] == nil {
+				cache[key] = make(map[licensing.Entry]bool)
 			}
-			cache[issn][e] = true
+			cache[key][e] = true
 		}
 	}
 	// Make unique.
 	result := make(map[string][]licensing.Entry)
-	for issn, entrymap := range cache {
+	for key, entrymap := range cache {
 		for k := range entrymap {
-			result[issn] = append(result[issn], k)
+			result[key] = append(result[key], k)
 		}
 	}
 	return result
 }
 
+// SerialNumberMap creates a map from ISSN to associated licensing entries.
+// This is here for performance mostly, so we can access relevant licensing
+// entry by ISSN.  XXX: Do not replicate entries, just index into them.
+func (h *Holdings) SerialNumberMap() map[string][]licensing.Entry {
+	return h.index(func(e licensing.Entry) []string { return e.ISSNList() })
+}
+
+// ISBNMap maps normalized ISBN-13 to a list of entries, for e-books.
+func (h *Holdings) ISBNMap() map[string][]licensing.Entry {
+	return h.index(func(e licensing.Entry) []string { return e.ISBNList() })
+}
+
+// DOIMap maps the lowercase DOI of a title, e.g. of a book, to a list of
+// entries. Records, whose DOI starts with the title DOI, e.g. chapters, can
+// be found via prefix lookups.
+func (h *Holdings) DOIMap() map[string][]licensing.Entry {
+	return h.index(func(e licensing.Entry) []string {
+		if doi := e.DOI(); doi != "" {
+			return []string{doi}
+		}
+		return nil
+	})
+}
+
 // TitleMap maps an exact title to a list of entries.
 func (h *Holdings) TitleMap() map[string][]licensing.Entry {
-	cache := make(map[string]map[licensing.Entry]bool)
-	for _, e := range *h {
-		if cache[e.PublicationTitle] == nil {
-			cache[e.PublicationTitle] = make(map[licensing.Entry]bool)
-		}
-		cache[e.PublicationTitle][e] = true
-	}
-	// Make unique.
-	result := make(map[string][]licensing.Entry)
-	for title, entrymap := range cache {
-		for k := range entrymap {
-			result[title] = append(result[title], k)
-		}
-	}
-	return result
+	return h.index(func(e licensing.Entry) []string { return []string{e.PublicationTitle} })
 }
 
 // WisoDatabaseMap derives a structure from the holdings file, that maps WISO
@@ -94,28 +103,14 @@ func (h *Holdings) WisoDatabaseMap() map[string][]licensing.Entry {
 		regexp.MustCompile(`https://www.wiso-net.de/.*dbShortcut=:2:2:([A-Z]{3,4})`),
 		regexp.MustCompile(`https://www.wiso-net.de/.*dbShortcut=([A-Z]{3,4})`),
 	}
-	cache := make(map[string]map[licensing.Entry]bool)
-	for _, e := range *h {
+	return h.index(func(e licensing.Entry) (dbs []string) {
 		for _, p := range patterns {
-			matches := p.FindStringSubmatch(e.TitleURL)
-			if len(matches) < 2 {
-				continue
-			}
-			db := matches[1]
-			if cache[db] == nil {
-				cache[db] = make(map[licensing.Entry]bool)
+			if matches := p.FindStringSubmatch(e.TitleURL); len(matches) > 1 {
+				dbs = append(dbs, matches[1])
 			}
-			cache[db][e] = true
-		}
-	}
-	// Make unique. XXX: Move this out.
-	result := make(map[string][]licensing.Entry)
-	for issn, entrymap := range cache {
-		for k := range entrymap {
-			result[issn] = append(result[issn], k)
 		}
-	}
-	return result
+		return dbs
+	})
 }
 
 // Filter finds entries with certain characteristics. This will be slow for KBART
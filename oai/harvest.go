@@ -0,0 +1,294 @@
+// Package oai implements an OAI-PMH harvester, http://www.openarchives.org/OAI/openarchivesprotocol.html.
+//
+// The harvester issues ListRecords requests, follows resumption tokens and
+// writes the raw responses into a single, concatenated XML stream, which
+// span-import can read directly. The harvest state (the last resumption token)
+// can be saved to a file after each page, so an interrupted harvest can be
+// resumed later.
+package oai
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"time"
+
+	"github.com/miku/span"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	// ErrStateMismatch signals a state file that belongs to a different harvest.
+	ErrStateMismatch = errors.New("state file belongs to a different harvest")
+
+	// xmlDeclaration is removed from each response, so the result is a
+	// sequence of XML documents without repeated declarations.
+	xmlDeclaration = regexp.MustCompile(`^\s*<\?xml[^>]*\?>\s*`)
+)
+
+// Error is an OAI-PMH protocol error, like badArgument or noRecordsMatch.
+type Error struct {
+	Code    string `xml:"code,attr"`
+	Message string `xml:",chardata"`
+}
+
+// Error returns the code and message.
+func (e Error) Error() string {
+	return fmt.Sprintf("oai: %s: %s", e.Code, e.Message)
+}
+
+// ResumptionToken with optional attributes.
+type ResumptionToken struct {
+	Text             string `xml:",chardata"`
+	CompleteListSize string `xml:"completeListSize,attr,omitempty"`
+	Cursor           string `xml:"cursor,attr,omitempty"`
+	ExpirationDate   string `xml:"expirationDate,attr,omitempty"`
+}
+
+// listRecordsResponse contains only the parts of a response the harvester cares about.
+type listRecordsResponse struct {
+	XMLName     xml.Name `xml:"OAI-PMH"`
+	Error       *Error   `xml:"error"`
+	ListRecords struct {
+		Records         []struct{}      `xml:"record"`
+		ResumptionToken ResumptionToken `xml:"resumptionToken"`
+	} `xml:"ListRecords"`
+}
+
+// State of a harvest, which can be saved to and restored from a file.
+type State struct {
+	Endpoint string `json:"endpoint"`
+	Prefix   string `json:"prefix"`
+	Set      string `json:"set,omitempty"`
+	From     string `json:"from,omitempty"`
+	Until    string `json:"until,omitempty"`
+	Token    string `json:"token,omitempty"`
+	Pages    int    `json:"pages"`
+	Records  int    `json:"records"`
+	// Size is the number of bytes written to the output with all saved
+	// pages. A page written after that is dropped on resume.
+	Size    int64     `json:"size"`
+	Done    bool      `json:"done"`
+	Updated time.Time `json:"updated"`
+}
+
+// sameHarvest returns true, if the other state describes the same request.
+func (s State) sameHarvest(o State) bool {
+	return s.Endpoint == o.Endpoint && s.Prefix == o.Prefix && s.Set == o.Set &&
+		s.From == o.From && s.Until == o.Until
+}
+
+// Harvester harvests records from an OAI-PMH endpoint. Requests are retried
+// according to the embedded Retrier.
+type Harvester struct {
+	span.Retrier
+	Endpoint string // base URL, e.g. http://www.ssoar.info/OAIHandler/request
+	Prefix   string // metadataPrefix, e.g. oai_dc or marcxml
+	Set      string // optional setSpec
+	From     string // optional lower bound, YYYY-MM-DD or full datestamp
+	Until    string // optional upper bound
+	// StateFile, if not empty, is used to persist progress after each page.
+	StateFile string
+
+	state State
+}
+
+// NewHarvester creates a harvester with default settings.
+func NewHarvester(endpoint, prefix string) *Harvester {
+	return &Harvester{
+		Retrier: span.Retrier{
+			MaxRetries: 5,
+			Backoff:    2 * time.Second,
+			Client:     &http.Client{Timeout: 5 * time.Minute},
+		},
+		Endpoint: endpoint,
+		Prefix:   prefix,
+	}
+}
+
+// State returns the current harvest state.
+func (h *Harvester) State() State {
+	return h.state
+}
+
+// loadState restores the state from the state file, if there is one. It
+// returns true, if a harvest can be resumed.
+func (h *Harvester) loadState() (resume bool, err error) {
+	h.state = State{
+		Endpoint: h.Endpoint,
+		Prefix:   h.Prefix,
+		Set:      h.Set,
+		From:     h.From,
+		Until:    h.Until,
+	}
+	if h.StateFile == "" {
+		return false, nil
+	}
+	b, err := ioutil.ReadFile(h.StateFile)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	var saved State
+	if err := json.Unmarshal(b, &saved); err != nil {
+		return false, err
+	}
+	if !saved.sameHarvest(h.state) {
+		return false, ErrStateMismatch
+	}
+	h.state = saved
+	return true, nil
+}
+
+// saveState writes the current state to the state file, if configured.
+func (h *Harvester) saveState() error {
+	if h.StateFile == "" {
+		return nil
+	}
+	h.state.Updated = time.Now()
+	b, err := json.MarshalIndent(h.state, "", "    ")
+	if err != nil {
+		return err
+	}
+	tmp := h.StateFile + ".tmp"
+	if err := ioutil.WriteFile(tmp, b, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, h.StateFile)
+}
+
+// requestURL returns the URL for the initial request or for a given
+// resumption token.
+func (h *Harvester) requestURL(token string) string {
+	v := url.Values{}
+	v.Set("verb", "ListRecords")
+	if token != "" {
+		v.Set("resumptionToken", token)
+	} else {
+		v.Set("metadataPrefix", h.Prefix)
+		if h.Set != "" {
+			v.Set("set", h.Set)
+		}
+		if h.From != "" {
+			v.Set("from", h.From)
+		}
+		if h.Until != "" {
+			v.Set("until", h.Until)
+		}
+	}
+	return fmt.Sprintf("%s?%s", h.Endpoint, v.Encode())
+}
+
+// fetch retrieves a URL with retries.
+func (h *Harvester) fetch(link string) ([]byte, error) {
+	return h.Do(func() (*http.Request, error) {
+		return http.NewRequest("GET", link, nil)
+	})
+}
+
+// truncater is implemented by files, so a page, that was written to the
+// output but not recorded in the state, can be removed on resume.
+type truncater interface {
+	Seek(offset int64, whence int) (int64, error)
+	Truncate(size int64) error
+}
+
+// truncate cuts the output back to the size recorded in the state.
+func (h *Harvester) truncate(w io.Writer) error {
+	f, ok := w.(truncater)
+	if !ok || h.state.Size == 0 {
+		// Nothing written, an output we cannot truncate or a state file
+		// without size.
+		return nil
+	}
+	size, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+	switch {
+	case size < h.state.Size:
+		return fmt.Errorf("output has %d bytes, state file expects at least %d", size, h.state.Size)
+	case size > h.state.Size:
+		log.Printf("[harvest] dropping %d bytes of an unsaved page", size-h.state.Size)
+		if err := f.Truncate(h.state.Size); err != nil {
+			return err
+		}
+		_, err = f.Seek(h.state.Size, io.SeekStart)
+		return err
+	}
+	return nil
+}
+
+// Run harvests all pages and writes the responses to w. If a state file
+// exists for the same harvest, the harvest continues with the saved
+// resumption token. If w is a file, anything written after the last saved
+// page is removed first. A finished harvest, according to the state file, is
+// not repeated.
+func (h *Harvester) Run(w io.Writer) error {
+	resume, err := h.loadState()
+	if err != nil {
+		return err
+	}
+	if resume {
+		if h.state.Done {
+			log.Printf("[harvest] already done: %d pages, %d records", h.state.Pages, h.state.Records)
+			return nil
+		}
+		log.Printf("[harvest] resuming after %d pages with token %s", h.state.Pages, h.state.Token)
+		if err := h.truncate(w); err != nil {
+			return err
+		}
+	}
+	for {
+		link := h.requestURL(h.state.Token)
+		b, err := h.fetch(link)
+		if err != nil {
+			return err
+		}
+		var resp listRecordsResponse
+		dec := xml.NewDecoder(bytes.NewReader(b))
+		dec.Strict = false
+		if err := dec.Decode(&resp); err != nil {
+			return fmt.Errorf("cannot parse response from %s: %v", link, err)
+		}
+		if resp.Error != nil {
+			if resp.Error.Code == "noRecordsMatch" {
+				log.Printf("[harvest] no records match")
+				h.state.Done = true
+				return h.saveState()
+			}
+			return *resp.Error
+		}
+		n, err := w.Write(append(xmlDeclaration.ReplaceAll(b, nil), '\n'))
+		if err != nil {
+			return err
+		}
+		if f, ok := w.(interface{ Sync() error }); ok {
+			if err := f.Sync(); err != nil {
+				return err
+			}
+		}
+		h.state.Pages++
+		h.state.Size += int64(n)
+		h.state.Records += len(resp.ListRecords.Records)
+		h.state.Token = resp.ListRecords.ResumptionToken.Text
+		h.state.Done = h.state.Token == ""
+		log.Printf("[harvest] page %d, %d records total", h.state.Pages, h.state.Records)
+		if err := h.saveState(); err != nil {
+			return err
+		}
+		if h.state.Done {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package oai
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// page renders a minimal ListRecords response.
+func page(ids []string, token string) string {
+	var buf bytes.Buffer
+	buf.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	buf.WriteString(`<OAI-PMH xmlns="http://www.openarchives.org/OAI/2.0/"><ListRecords>`)
+	for _, id := range ids {
+		fmt.Fprintf(&buf, `<record><header><identifier>%s</identifier></header><metadata/></record>`, id)
+	}
+	fmt.Fprintf(&buf, `<resumptionToken>%s</resumptionToken></ListRecords></OAI-PMH>`, token)
+	return buf.String()
+}
+
+// stub returns a test server, serving three pages. The second request fails
+// once with 503, to exercise retries. If broken is true, the third page is
+// never served.
+func stub(t *testing.T, broken *bool) *httptest.Server {
+	failed := false
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("verb") != "ListRecords" {
+			t.Errorf("unexpected verb: %s", q.Get("verb"))
+		}
+		switch q.Get("resumptionToken") {
+		case "":
+			if q.Get("metadataPrefix") != "oai_dc" || q.Get("set") != "s" {
+				t.Errorf("unexpected initial request: %s", r.URL.RawQuery)
+			}
+			fmt.Fprint(w, page([]string{"a", "b"}, "t1"))
+		case "t1":
+			if !failed {
+				failed = true
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			fmt.Fprint(w, page([]string{"c"}, "t2"))
+		case "t2":
+			if *broken {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			fmt.Fprint(w, page([]string{"d"}, ""))
+		default:
+			fmt.Fprint(w, `<OAI-PMH><error code="badResumptionToken">invalid</error></OAI-PMH>`)
+		}
+	}))
+}
+
+func TestHarvesterResume(t *testing.T) {
+	broken := true
+	ts := stub(t, &broken)
+	defer ts.Close()
+
+	dir, err := ioutil.TempDir("", "span-oai-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	newHarvester := func() *Harvester {
+		h := NewHarvester(ts.URL, "oai_dc")
+		h.Set = "s"
+		h.Backoff = 0
+		h.MaxRetries = 1
+		h.StateFile = filepath.Join(dir, "state.json")
+		return h
+	}
+	var buf bytes.Buffer
+	h := newHarvester()
+	if err := h.Run(&buf); err == nil {
+		t.Fatalf("expected error on broken endpoint")
+	}
+	if s := h.State(); s.Pages != 2 || s.Records != 3 || s.Token != "t2" || s.Done {
+		t.Fatalf("unexpected state after failure: %+v", s)
+	}
+
+	broken = false
+	h = newHarvester()
+	if err := h.Run(&buf); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if s := h.State(); s.Pages != 3 || s.Records != 4 || !s.Done {
+		t.Fatalf("unexpected state after resume: %+v", s)
+	}
+	if n := strings.Count(buf.String(), "<record>"); n != 4 {
+		t.Errorf("got %d records in output, want 4", n)
+	}
+	if strings.Contains(buf.String(), "<?xml") {
+		t.Errorf("output should not contain XML declarations")
+	}
+
+	h = newHarvester()
+	h.Set = "other"
+	if err := h.Run(&buf); err != ErrStateMismatch {
+		t.Errorf("got %v, want ErrStateMismatch", err)
+	}
+}
+
+func TestHarvesterUnsavedPage(t *testing.T) {
+	broken := true
+	ts := stub(t, &broken)
+	defer ts.Close()
+
+	dir, err := ioutil.TempDir("", "span-oai-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	output := filepath.Join(dir, "output.xml")
+	run := func() error {
+		f, err := os.OpenFile(output, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		h := NewHarvester(ts.URL, "oai_dc")
+		h.Set = "s"
+		h.Backoff = 0
+		h.MaxRetries = 1
+		h.StateFile = filepath.Join(dir, "state.json")
+		return h.Run(f)
+	}
+	if err := run(); err == nil {
+		t.Fatalf("expected error on broken endpoint")
+	}
+	// A page written, before the harvest was interrupted.
+	f, err := os.OpenFile(output, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString(page([]string{"d"}, "")); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	broken = false
+	if err := run(); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	b, err := ioutil.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(b), "<record>"); n != 4 {
+		t.Errorf("got %d records in output, want 4", n)
+	}
+	if strings.Contains(string(b), "<?xml") {
+		t.Errorf("unsaved page should have been removed")
+	}
+
+	// An output file shorter than recorded is an error.
+	if err := os.Truncate(output, 10); err != nil {
+		t.Fatal(err)
+	}
+	b, err = ioutil.ReadFile(filepath.Join(dir, "state.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "state.json"), bytes.Replace(b, []byte(`"done": true`), []byte(`"done": false`), 1), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := run(); err == nil {
+		t.Errorf("expected error for truncated output")
+	}
+}
+
+func TestHarvesterError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("set") {
+		case "empty":
+			fmt.Fprint(w, `<OAI-PMH><error code="noRecordsMatch">no records</error></OAI-PMH>`)
+		default:
+			fmt.Fprint(w, `<OAI-PMH><error code="badArgument">bad</error></OAI-PMH>`)
+		}
+	}))
+	defer ts.Close()
+
+	var buf bytes.Buffer
+	h := NewHarvester(ts.URL, "oai_dc")
+	h.Set = "empty"
+	if err := h.Run(&buf); err != nil {
+		t.Errorf("noRecordsMatch: got %v, want nil", err)
+	}
+	h.Set = "x"
+	err := h.Run(&buf)
+	if e, ok := err.(Error); !ok || e.Code != "badArgument" {
+		t.Errorf("got %v, want badArgument error", err)
+	}
+}
@@ -0,0 +1,791 @@
+package oai
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/miku/span/formats/finc"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// Namespace of OAI-PMH responses.
+	Namespace = "http://www.openarchives.org/OAI/2.0/"
+
+	dayLayout     = "2006-01-02"
+	secondsLayout = "2006-01-02T15:04:05Z"
+)
+
+// MetadataFormat is a metadata format served by a provider.
+type MetadataFormat struct {
+	Prefix    string `xml:"metadataPrefix"`
+	Schema    string `xml:"schema"`
+	Namespace string `xml:"metadataNamespace"`
+}
+
+// MetadataFormats are the formats served by a provider: Dublin Core and the
+// intermediate schema, as JSON wrapped in an XML element.
+var MetadataFormats = []MetadataFormat{
+	{
+		Prefix:    "oai_dc",
+		Schema:    "http://www.openarchives.org/OAI/2.0/oai_dc.xsd",
+		Namespace: "http://www.openarchives.org/OAI/2.0/oai_dc/",
+	},
+	{
+		Prefix:    "is",
+		Schema:    "https://github.com/miku/span/blob/master/schema/is-0.9.json",
+		Namespace: "https://github.com/miku/span/schema/",
+	},
+}
+
+// Set is a named set of records.
+type Set struct {
+	Spec string `xml:"setSpec"`
+	Name string `xml:"setName"`
+}
+
+// verbs lists required and optional arguments of each verb. A resumption
+// token is always exclusive.
+var verbs = map[string]struct {
+	required []string
+	optional []string
+}{
+	"Identify":            {},
+	"ListMetadataFormats": {optional: []string{"identifier"}},
+	"ListSets":            {optional: []string{"resumptionToken"}},
+	"ListIdentifiers":     {required: []string{"metadataPrefix"}, optional: []string{"from", "until", "set", "resumptionToken"}},
+	"ListRecords":         {required: []string{"metadataPrefix"}, optional: []string{"from", "until", "set", "resumptionToken"}},
+	"GetRecord":           {required: []string{"identifier", "metadataPrefix"}},
+}
+
+// header of a record.
+type header struct {
+	Identifier string   `xml:"identifier"`
+	Datestamp  string   `xml:"datestamp"`
+	SetSpecs   []string `xml:"setSpec"`
+}
+
+// record with header and metadata.
+type record struct {
+	Header   header `xml:"header"`
+	Metadata struct {
+		Inner []byte `xml:",innerxml"`
+	} `xml:"metadata"`
+}
+
+// request echoes the arguments of a request.
+type request struct {
+	Verb            string `xml:"verb,attr,omitempty"`
+	Identifier      string `xml:"identifier,attr,omitempty"`
+	MetadataPrefix  string `xml:"metadataPrefix,attr,omitempty"`
+	From            string `xml:"from,attr,omitempty"`
+	Until           string `xml:"until,attr,omitempty"`
+	Set             string `xml:"set,attr,omitempty"`
+	ResumptionToken string `xml:"resumptionToken,attr,omitempty"`
+	URL             string `xml:",chardata"`
+}
+
+// identify describes the repository.
+type identify struct {
+	RepositoryName    string `xml:"repositoryName"`
+	BaseURL           string `xml:"baseURL"`
+	ProtocolVersion   string `xml:"protocolVersion"`
+	AdminEmail        string `xml:"adminEmail"`
+	EarliestDatestamp string `xml:"earliestDatestamp"`
+	DeletedRecord     string `xml:"deletedRecord"`
+	Granularity       string `xml:"granularity"`
+}
+
+// list is the payload of list verbs, with an optional resumption token.
+type list struct {
+	Sets    []Set            `xml:"set,omitempty"`
+	Headers []header         `xml:"header,omitempty"`
+	Records []record         `xml:"record,omitempty"`
+	Token   *ResumptionToken `xml:"resumptionToken"`
+}
+
+// response is an OAI-PMH response.
+type response struct {
+	XMLName             xml.Name `xml:"OAI-PMH"`
+	Xmlns               string   `xml:"xmlns,attr"`
+	XmlnsXsi            string   `xml:"xmlns:xsi,attr"`
+	SchemaLocation      string   `xml:"xsi:schemaLocation,attr"`
+	ResponseDate        string   `xml:"responseDate"`
+	Request             request  `xml:"request"`
+	Errors              []Error  `xml:"error"`
+	Identify            *identify
+	ListMetadataFormats *struct {
+		Formats []MetadataFormat `xml:"metadataFormat"`
+	}
+	ListSets        *list
+	ListIdentifiers *list
+	ListRecords     *list
+	GetRecord       *struct {
+		Record record `xml:"record"`
+	}
+}
+
+// dublinCore is an oai_dc record.
+type dublinCore struct {
+	XMLName        xml.Name `xml:"oai_dc:dc"`
+	XmlnsOAIDC     string   `xml:"xmlns:oai_dc,attr"`
+	XmlnsDC        string   `xml:"xmlns:dc,attr"`
+	XmlnsXsi       string   `xml:"xmlns:xsi,attr"`
+	SchemaLocation string   `xml:"xsi:schemaLocation,attr"`
+	Title          []string `xml:"dc:title"`
+	Creator        []string `xml:"dc:creator"`
+	Contributor    []string `xml:"dc:contributor"`
+	Subject        []string `xml:"dc:subject"`
+	Description    []string `xml:"dc:description"`
+	Publisher      []string `xml:"dc:publisher"`
+	Date           []string `xml:"dc:date"`
+	Type           []string `xml:"dc:type"`
+	Identifier     []string `xml:"dc:identifier"`
+	Source         []string `xml:"dc:source"`
+	Language       []string `xml:"dc:language"`
+	Rights         []string `xml:"dc:rights"`
+}
+
+// nativeRecord wraps an intermediate schema record.
+type nativeRecord struct {
+	XMLName xml.Name `xml:"intermediate"`
+	Xmlns   string   `xml:"xmlns,attr"`
+	Data    string   `xml:",cdata"`
+}
+
+// entry locates a record in a file.
+type entry struct {
+	id        string // empty, if replaced by a later record with the same id
+	file      int
+	offset    int64
+	length    int
+	datestamp time.Time
+	sets      []string
+}
+
+// Provider serves intermediate schema records from newline delimited files
+// over OAI-PMH. Records are indexed by finc.id, the datestamp of a record is
+// the modification time of its file. Sets are source ids, collections and
+// labels, e.g. source:49, collection:Crossref or label:DE-15.
+type Provider struct {
+	RepositoryName string
+	BaseURL        string
+	AdminEmail     string
+	// Identifier is the repository part of OAI identifiers, e.g. finc.info
+	// for oai:finc.info:ai-49-aHR0...
+	Identifier string
+	// PageSize is the number of records or headers per response.
+	PageSize int
+	// Labels, if not empty, restricts the records served to those with at
+	// least one of these labels, e.g. ISIL.
+	Labels []string
+
+	files   []*os.File
+	entries []entry
+	byID    map[string]int
+	sets    map[string]string
+}
+
+// NewProvider creates a provider with default settings.
+func NewProvider(baseURL string) *Provider {
+	return &Provider{
+		RepositoryName: "span",
+		BaseURL:        baseURL,
+		AdminEmail:     "admin@localhost",
+		Identifier:     "span",
+		PageSize:       100,
+		byID:           make(map[string]int),
+		sets: map[string]string{
+			"source":     "Sources",
+			"collection": "Collections",
+			"label":      "Labels",
+		},
+	}
+}
+
+// setSpec replaces characters not allowed in a set spec.
+func setSpec(prefix, s string) string {
+	spec := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		case strings.ContainsRune("-_.!~*'()", r):
+			return r
+		}
+		return '_'
+	}, s)
+	return prefix + ":" + spec
+}
+
+// allowed returns true, if a record with the given labels may be served.
+func (p *Provider) allowed(labels []string) bool {
+	if len(p.Labels) == 0 {
+		return true
+	}
+	for _, l := range labels {
+		for _, m := range p.Labels {
+			if l == m {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// AddFile indexes an uncompressed intermediate schema file. The file is kept
+// open, records are read on demand. Later records replace earlier ones with
+// the same id.
+func (p *Provider) AddFile(filename string) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	datestamp := fi.ModTime().UTC().Truncate(time.Second)
+	p.files = append(p.files, f)
+	br := bufio.NewReader(f)
+	var offset int64
+	var n int
+	for {
+		line, err := br.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if len(bytes.TrimSpace(line)) > 0 {
+			var doc struct {
+				ID              string   `json:"finc.id"`
+				SourceID        string   `json:"finc.source_id"`
+				MegaCollections []string `json:"finc.mega_collection"`
+				Labels          []string `json:"x.labels"`
+			}
+			if err := json.Unmarshal(line, &doc); err != nil {
+				return fmt.Errorf("%s at offset %d: %v", filename, offset, err)
+			}
+			if doc.ID != "" && p.allowed(doc.Labels) {
+				e := entry{
+					id:        doc.ID,
+					file:      len(p.files) - 1,
+					offset:    offset,
+					length:    len(line),
+					datestamp: datestamp,
+				}
+				if doc.SourceID != "" {
+					e.sets = append(e.sets, setSpec("source", doc.SourceID))
+					p.sets[setSpec("source", doc.SourceID)] = "finc.source_id " + doc.SourceID
+				}
+				for _, c := range doc.MegaCollections {
+					e.sets = append(e.sets, setSpec("collection", c))
+					p.sets[setSpec("collection", c)] = c
+				}
+				for _, l := range doc.Labels {
+					if len(p.Labels) > 0 && !p.allowed([]string{l}) {
+						continue
+					}
+					e.sets = append(e.sets, setSpec("label", l))
+					p.sets[setSpec("label", l)] = l
+				}
+				if i, ok := p.byID[doc.ID]; ok {
+					p.entries[i].id = ""
+				}
+				p.byID[doc.ID] = len(p.entries)
+				p.entries = append(p.entries, e)
+				n++
+			}
+		}
+		offset += int64(len(line))
+		if err == io.EOF {
+			break
+		}
+	}
+	log.Printf("[oai] %s: %d records indexed", filename, n)
+	return nil
+}
+
+// Close closes all indexed files.
+func (p *Provider) Close() error {
+	var err error
+	for _, f := range p.files {
+		if e := f.Close(); e != nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// oaiIdentifier returns the OAI identifier for a record id.
+func (p *Provider) oaiIdentifier(id string) string {
+	return fmt.Sprintf("oai:%s:%s", p.Identifier, id)
+}
+
+// lookup returns the entry for an OAI identifier.
+func (p *Provider) lookup(identifier string) (entry, bool) {
+	prefix := fmt.Sprintf("oai:%s:", p.Identifier)
+	if !strings.HasPrefix(identifier, prefix) {
+		return entry{}, false
+	}
+	i, ok := p.byID[strings.TrimPrefix(identifier, prefix)]
+	if !ok {
+		return entry{}, false
+	}
+	return p.entries[i], true
+}
+
+// header returns the record header of an entry.
+func (p *Provider) header(e entry) header {
+	return header{
+		Identifier: p.oaiIdentifier(e.id),
+		Datestamp:  e.datestamp.Format(secondsLayout),
+		SetSpecs:   e.sets,
+	}
+}
+
+// record reads a record from disk and renders its metadata.
+func (p *Provider) record(e entry, prefix string) (record, error) {
+	b := make([]byte, e.length)
+	if _, err := p.files[e.file].ReadAt(b, e.offset); err != nil {
+		return record{}, err
+	}
+	var r record
+	r.Header = p.header(e)
+	var v interface{}
+	switch prefix {
+	case "is":
+		v = nativeRecord{Xmlns: MetadataFormats[1].Namespace, Data: string(bytes.TrimSpace(b))}
+	default:
+		var is finc.IntermediateSchema
+		if err := json.Unmarshal(b, &is); err != nil {
+			return record{}, err
+		}
+		v = newDublinCore(is)
+	}
+	inner, err := xml.Marshal(v)
+	if err != nil {
+		return record{}, err
+	}
+	r.Metadata.Inner = inner
+	return r, nil
+}
+
+// newDublinCore maps an intermediate schema record to oai_dc.
+func newDublinCore(is finc.IntermediateSchema) dublinCore {
+	dc := dublinCore{
+		XmlnsOAIDC:     "http://www.openarchives.org/OAI/2.0/oai_dc/",
+		XmlnsDC:        "http://purl.org/dc/elements/1.1/",
+		XmlnsXsi:       "http://www.w3.org/2001/XMLSchema-instance",
+		SchemaLocation: "http://www.openarchives.org/OAI/2.0/oai_dc/ http://www.openarchives.org/OAI/2.0/oai_dc.xsd",
+		Subject:        is.Subjects,
+		Publisher:      is.Publishers,
+		Language:       is.Languages,
+		Rights:         is.License,
+	}
+	appendNonEmpty := func(s *[]string, values ...string) {
+		for _, v := range values {
+			if v = strings.TrimSpace(v); v != "" {
+				*s = append(*s, v)
+			}
+		}
+	}
+	if is.ArticleTitle != "" {
+		appendNonEmpty(&dc.Title, is.ArticleTitle)
+	} else {
+		appendNonEmpty(&dc.Title, is.BookTitle)
+	}
+	for _, author := range is.Authors {
+		if author.IsPrimary() {
+			appendNonEmpty(&dc.Creator, author.String())
+		} else {
+			appendNonEmpty(&dc.Contributor, author.String())
+		}
+	}
+	appendNonEmpty(&dc.Description, is.Abstract)
+	if !is.Date.IsZero() {
+		appendNonEmpty(&dc.Date, is.Date.Format(dayLayout))
+	} else {
+		appendNonEmpty(&dc.Date, is.RawDate)
+	}
+	appendNonEmpty(&dc.Type, is.Format)
+	if is.DOI != "" {
+		appendNonEmpty(&dc.Identifier, "https://doi.org/"+is.DOI)
+	}
+	appendNonEmpty(&dc.Identifier, is.URL...)
+	for _, issn := range append(append([]string(nil), is.ISSN...), is.EISSN...) {
+		appendNonEmpty(&dc.Identifier, "urn:issn:"+issn)
+	}
+	for _, isbn := range append(append([]string(nil), is.ISBN...), is.EISBN...) {
+		appendNonEmpty(&dc.Identifier, "urn:isbn:"+isbn)
+	}
+	if is.ArticleTitle != "" {
+		appendNonEmpty(&dc.Source, is.JournalTitle)
+	}
+	return dc
+}
+
+// earliestDatestamp returns the oldest datestamp.
+func (p *Provider) earliestDatestamp() time.Time {
+	var t time.Time
+	for _, e := range p.entries {
+		if t.IsZero() || e.datestamp.Before(t) {
+			t = e.datestamp
+		}
+	}
+	if t.IsZero() {
+		t = time.Now().UTC().Truncate(time.Second)
+	}
+	return t
+}
+
+// parseDatestamp parses a datestamp with day or seconds granularity. Until
+// dates with day granularity include the whole day.
+func parseDatestamp(s string, until bool) (t time.Time, day bool, err error) {
+	if t, err = time.Parse(dayLayout, s); err == nil {
+		if until {
+			t = t.Add(24*time.Hour - time.Second)
+		}
+		return t, true, nil
+	}
+	t, err = time.Parse(secondsLayout, s)
+	return t, false, err
+}
+
+// listQuery holds the arguments of a list request and the position within
+// the list, it is encoded in resumption tokens.
+type listQuery struct {
+	Prefix    string
+	Set       string
+	From      string
+	Until     string
+	Position  int // next entry to look at
+	Cursor    int // number of items already delivered
+	TotalSize int
+}
+
+// encode returns the query as resumption token.
+func (q listQuery) encode() string {
+	s := strings.Join([]string{q.Prefix, q.Set, q.From, q.Until,
+		strconv.Itoa(q.Position), strconv.Itoa(q.Cursor), strconv.Itoa(q.TotalSize)}, "|")
+	return base64.RawURLEncoding.EncodeToString([]byte(s))
+}
+
+// decodeListQuery parses a resumption token.
+func decodeListQuery(token string) (q listQuery, err error) {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return q, err
+	}
+	parts := strings.Split(string(b), "|")
+	if len(parts) != 7 {
+		return q, fmt.Errorf("invalid token")
+	}
+	q.Prefix, q.Set, q.From, q.Until = parts[0], parts[1], parts[2], parts[3]
+	var ints [3]int
+	for i, s := range parts[4:] {
+		if ints[i], err = strconv.Atoi(s); err != nil || ints[i] < 0 {
+			return q, fmt.Errorf("invalid token")
+		}
+	}
+	q.Position, q.Cursor, q.TotalSize = ints[0], ints[1], ints[2]
+	return q, nil
+}
+
+// matcher returns a function, that checks whether an entry matches the
+// query, or a protocol error.
+func (p *Provider) matcher(q listQuery) (func(entry) bool, *Error) {
+	var from, until time.Time
+	var fromDay, untilDay bool
+	var err error
+	if q.From != "" {
+		if from, fromDay, err = parseDatestamp(q.From, false); err != nil {
+			return nil, &Error{Code: "badArgument", Message: "invalid from: " + q.From}
+		}
+	}
+	if q.Until != "" {
+		if until, untilDay, err = parseDatestamp(q.Until, true); err != nil {
+			return nil, &Error{Code: "badArgument", Message: "invalid until: " + q.Until}
+		}
+	}
+	if q.From != "" && q.Until != "" {
+		if fromDay != untilDay {
+			return nil, &Error{Code: "badArgument", Message: "from and until differ in granularity"}
+		}
+		if from.After(until) {
+			return nil, &Error{Code: "badArgument", Message: "from is after until"}
+		}
+	}
+	if _, ok := p.sets[q.Set]; q.Set != "" && !ok {
+		return nil, &Error{Code: "noRecordsMatch", Message: "unknown set: " + q.Set}
+	}
+	return func(e entry) bool {
+		if e.id == "" {
+			return false
+		}
+		if !from.IsZero() && e.datestamp.Before(from) {
+			return false
+		}
+		if !until.IsZero() && e.datestamp.After(until) {
+			return false
+		}
+		if q.Set == "" {
+			return true
+		}
+		for _, s := range e.sets {
+			if s == q.Set || strings.HasPrefix(s, q.Set+":") {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+// supportedFormat returns true, if a metadata prefix is served.
+func supportedFormat(prefix string) bool {
+	for _, f := range MetadataFormats {
+		if f.Prefix == prefix {
+			return true
+		}
+	}
+	return false
+}
+
+// list handles ListIdentifiers and ListRecords.
+func (p *Provider) list(q listQuery, withRecords bool) (*list, *Error) {
+	if !supportedFormat(q.Prefix) {
+		return nil, &Error{Code: "cannotDisseminateFormat", Message: q.Prefix}
+	}
+	match, oerr := p.matcher(q)
+	if oerr != nil {
+		return nil, oerr
+	}
+	if q.Position == 0 && q.Cursor == 0 {
+		for _, e := range p.entries {
+			if match(e) {
+				q.TotalSize++
+			}
+		}
+	}
+	if q.TotalSize == 0 {
+		return nil, &Error{Code: "noRecordsMatch", Message: "no records match"}
+	}
+	size := p.PageSize
+	if size < 1 {
+		size = 100
+	}
+	result := &list{}
+	first := q.Cursor
+	var n int
+	for q.Position < len(p.entries) && n < size {
+		e := p.entries[q.Position]
+		q.Position++
+		if !match(e) {
+			continue
+		}
+		n++
+		if withRecords {
+			r, err := p.record(e, q.Prefix)
+			if err != nil {
+				log.Printf("[oai] %s: %v", e.id, err)
+				return nil, &Error{Code: "badArgument", Message: "record cannot be read: " + e.id}
+			}
+			result.Records = append(result.Records, r)
+		} else {
+			result.Headers = append(result.Headers, p.header(e))
+		}
+	}
+	q.Cursor += n
+	switch {
+	case q.Cursor < q.TotalSize && q.Position < len(p.entries):
+		result.Token = &ResumptionToken{
+			Text:             q.encode(),
+			CompleteListSize: strconv.Itoa(q.TotalSize),
+			Cursor:           strconv.Itoa(first),
+		}
+	case first > 0:
+		// The last page of an incomplete list has an empty token.
+		result.Token = &ResumptionToken{
+			CompleteListSize: strconv.Itoa(q.TotalSize),
+			Cursor:           strconv.Itoa(first),
+		}
+	}
+	return result, nil
+}
+
+// checkArguments validates the arguments of a request.
+func checkArguments(form url.Values) *Error {
+	verb := form.Get("verb")
+	if len(form["verb"]) != 1 {
+		return &Error{Code: "badVerb", Message: "exactly one verb required"}
+	}
+	args, ok := verbs[verb]
+	if !ok {
+		return &Error{Code: "badVerb", Message: "illegal verb: " + verb}
+	}
+	allowed := make(map[string]bool)
+	for _, a := range append(args.required, args.optional...) {
+		allowed[a] = true
+	}
+	for k, v := range form {
+		if k == "verb" {
+			continue
+		}
+		if !allowed[k] {
+			return &Error{Code: "badArgument", Message: "illegal argument: " + k}
+		}
+		if len(v) > 1 {
+			return &Error{Code: "badArgument", Message: "repeated argument: " + k}
+		}
+	}
+	if _, ok := form["resumptionToken"]; ok {
+		if len(form) > 2 {
+			return &Error{Code: "badArgument", Message: "resumptionToken is an exclusive argument"}
+		}
+		return nil
+	}
+	for _, a := range args.required {
+		if form.Get(a) == "" {
+			return &Error{Code: "badArgument", Message: "missing argument: " + a}
+		}
+	}
+	return nil
+}
+
+// handle answers a request.
+func (p *Provider) handle(form url.Values) *response {
+	resp := &response{
+		Xmlns:          Namespace,
+		XmlnsXsi:       "http://www.w3.org/2001/XMLSchema-instance",
+		SchemaLocation: Namespace + " http://www.openarchives.org/OAI/2.0/OAI-PMH.xsd",
+		ResponseDate:   time.Now().UTC().Format(secondsLayout),
+		Request:        request{URL: p.BaseURL},
+	}
+	if oerr := checkArguments(form); oerr != nil {
+		resp.Errors = append(resp.Errors, *oerr)
+		return resp
+	}
+	resp.Request = request{
+		Verb:            form.Get("verb"),
+		Identifier:      form.Get("identifier"),
+		MetadataPrefix:  form.Get("metadataPrefix"),
+		From:            form.Get("from"),
+		Until:           form.Get("until"),
+		Set:             form.Get("set"),
+		ResumptionToken: form.Get("resumptionToken"),
+		URL:             p.BaseURL,
+	}
+	var oerr *Error
+	switch form.Get("verb") {
+	case "Identify":
+		resp.Identify = &identify{
+			RepositoryName:    p.RepositoryName,
+			BaseURL:           p.BaseURL,
+			ProtocolVersion:   "2.0",
+			AdminEmail:        p.AdminEmail,
+			EarliestDatestamp: p.earliestDatestamp().Format(secondsLayout),
+			DeletedRecord:     "no",
+			Granularity:       "YYYY-MM-DDThh:mm:ssZ",
+		}
+	case "ListMetadataFormats":
+		if id := form.Get("identifier"); id != "" {
+			if _, ok := p.lookup(id); !ok {
+				oerr = &Error{Code: "idDoesNotExist", Message: id}
+				break
+			}
+		}
+		resp.ListMetadataFormats = &struct {
+			Formats []MetadataFormat `xml:"metadataFormat"`
+		}{Formats: MetadataFormats}
+	case "ListSets":
+		if form.Get("resumptionToken") != "" {
+			oerr = &Error{Code: "badResumptionToken", Message: "sets are not paged"}
+			break
+		}
+		var specs []string
+		for spec := range p.sets {
+			specs = append(specs, spec)
+		}
+		sort.Strings(specs)
+		resp.ListSets = &list{}
+		for _, spec := range specs {
+			resp.ListSets.Sets = append(resp.ListSets.Sets, Set{Spec: spec, Name: p.sets[spec]})
+		}
+	case "ListIdentifiers", "ListRecords":
+		q := listQuery{
+			Prefix: form.Get("metadataPrefix"),
+			Set:    form.Get("set"),
+			From:   form.Get("from"),
+			Until:  form.Get("until"),
+		}
+		if token := form.Get("resumptionToken"); token != "" {
+			var err error
+			if q, err = decodeListQuery(token); err != nil {
+				oerr = &Error{Code: "badResumptionToken", Message: token}
+				break
+			}
+		}
+		var l *list
+		if l, oerr = p.list(q, form.Get("verb") == "ListRecords"); oerr == nil {
+			if form.Get("verb") == "ListRecords" {
+				resp.ListRecords = l
+			} else {
+				resp.ListIdentifiers = l
+			}
+		}
+	case "GetRecord":
+		prefix := form.Get("metadataPrefix")
+		e, ok := p.lookup(form.Get("identifier"))
+		switch {
+		case !ok:
+			oerr = &Error{Code: "idDoesNotExist", Message: form.Get("identifier")}
+		case !supportedFormat(prefix):
+			oerr = &Error{Code: "cannotDisseminateFormat", Message: prefix}
+		default:
+			r, err := p.record(e, prefix)
+			if err != nil {
+				log.Printf("[oai] %s: %v", e.id, err)
+				oerr = &Error{Code: "idDoesNotExist", Message: "record cannot be read: " + e.id}
+				break
+			}
+			resp.GetRecord = &struct {
+				Record record `xml:"record"`
+			}{Record: r}
+		}
+	}
+	if oerr != nil {
+		resp.Errors = append(resp.Errors, *oerr)
+	}
+	return resp
+}
+
+// ServeHTTP answers OAI-PMH requests, sent with GET or POST.
+func (p *Provider) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	form := r.Form
+	if r.Method == "POST" {
+		form = r.PostForm
+	}
+	b, err := xml.Marshal(p.handle(form))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+	io.WriteString(w, xml.Header)
+	w.Write(b)
+}
@@ -0,0 +1,142 @@
+package oai
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// provider returns a provider serving five records from a temporary file.
+func provider(t *testing.T, labels ...string) (*Provider, func()) {
+	dir, err := ioutil.TempDir("", "span-oai-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lines []string
+	for i, label := range []string{"DE-15", "DE-14", "DE-15", "DE-15", "DE-14"} {
+		lines = append(lines, fmt.Sprintf(`{"finc.id": "ai-49-%d", "finc.source_id": "49", `+
+			`"finc.mega_collection": ["Crossref (Test)"], "x.labels": [%q], `+
+			`"rft.atitle": "Title %d", "rft.jtitle": "Journal", "authors": [{"rft.aulast": "Doe", "rft.aufirst": "J"}], `+
+			`"doi": "10.1/%d", "x.date": "2017-01-01T00:00:00Z"}`, i, label, i, i))
+	}
+	filename := filepath.Join(dir, "records.ldj")
+	if err := ioutil.WriteFile(filename, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	p := NewProvider("http://localhost/oai")
+	p.PageSize = 2
+	p.Labels = labels
+	if err := p.AddFile(filename); err != nil {
+		t.Fatal(err)
+	}
+	return p, func() {
+		p.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+// get sends a request and returns the response body.
+func get(t *testing.T, ts *httptest.Server, query string) string {
+	resp, err := http.Get(ts.URL + "?" + query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+var tokenPattern = regexp.MustCompile(`<resumptionToken[^>]*>([^<]*)</resumptionToken>`)
+
+func TestProviderListRecords(t *testing.T) {
+	p, cleanup := provider(t)
+	defer cleanup()
+	ts := httptest.NewServer(p)
+	defer ts.Close()
+
+	var ids []string
+	query := "verb=ListRecords&metadataPrefix=oai_dc&set=source:49"
+	for i := 0; i < 5; i++ {
+		body := get(t, ts, query)
+		if strings.Contains(body, "<error") {
+			t.Fatalf("unexpected error: %s", body)
+		}
+		ids = append(ids, regexp.MustCompile(`<identifier>([^<]*)</identifier>`).FindAllString(body, -1)...)
+		m := tokenPattern.FindStringSubmatch(body)
+		if m == nil || m[1] == "" {
+			break
+		}
+		query = "verb=ListRecords&resumptionToken=" + url.QueryEscape(m[1])
+	}
+	if len(ids) != 5 {
+		t.Errorf("got %d records, want 5", len(ids))
+	}
+
+	body := get(t, ts, "verb=ListIdentifiers&metadataPrefix=oai_dc&from=2000-01-01T00:00:00Z&until=2000-01-02")
+	if !strings.Contains(body, `code="badArgument"`) {
+		t.Errorf("expected badArgument for mixed granularity: %s", body)
+	}
+	body = get(t, ts, "verb=ListIdentifiers&metadataPrefix=oai_dc&until=2000-01-01")
+	if !strings.Contains(body, `code="noRecordsMatch"`) {
+		t.Errorf("expected noRecordsMatch: %s", body)
+	}
+	body = get(t, ts, "verb=ListRecords&metadataPrefix=marc")
+	if !strings.Contains(body, `code="cannotDisseminateFormat"`) {
+		t.Errorf("expected cannotDisseminateFormat: %s", body)
+	}
+	body = get(t, ts, "verb=ListRecords&metadataPrefix=oai_dc&resumptionToken=x")
+	if !strings.Contains(body, `code="badArgument"`) {
+		t.Errorf("expected badArgument for non-exclusive token: %s", body)
+	}
+	body = get(t, ts, "verb=ListRecords&resumptionToken=x")
+	if !strings.Contains(body, `code="badResumptionToken"`) {
+		t.Errorf("expected badResumptionToken: %s", body)
+	}
+}
+
+func TestProviderGetRecord(t *testing.T) {
+	p, cleanup := provider(t, "DE-14")
+	defer cleanup()
+	ts := httptest.NewServer(p)
+	defer ts.Close()
+
+	var cases = []struct {
+		query string
+		want  []string
+	}{
+		{"verb=Identify", []string{"<repositoryName>span</repositoryName>", "<granularity>YYYY-MM-DDThh:mm:ssZ</granularity>"}},
+		{"verb=Foo", []string{`code="badVerb"`}},
+		{"verb=GetRecord&identifier=oai:span:ai-49-1", []string{`code="badArgument"`}},
+		{"verb=GetRecord&identifier=oai:span:ai-49-1&metadataPrefix=oai_dc", []string{
+			"<dc:title>Title 1</dc:title>", "<dc:creator>Doe, J</dc:creator>",
+			"<dc:identifier>https://doi.org/10.1/1</dc:identifier>", "<dc:date>2017-01-01</dc:date>",
+			"<setSpec>collection:Crossref_(Test)</setSpec>", "<setSpec>label:DE-14</setSpec>",
+		}},
+		{"verb=GetRecord&identifier=oai:span:ai-49-1&metadataPrefix=is", []string{`<![CDATA[{"finc.id": "ai-49-1"`}},
+		// Records without label DE-14 are not served.
+		{"verb=GetRecord&identifier=oai:span:ai-49-0&metadataPrefix=is", []string{`code="idDoesNotExist"`}},
+		{"verb=ListMetadataFormats&identifier=oai:span:ai-49-4", []string{"<metadataPrefix>is</metadataPrefix>"}},
+		{"verb=ListSets", []string{"<setSpec>source:49</setSpec>", "<setName>Crossref (Test)</setName>"}},
+	}
+	for _, c := range cases {
+		body := get(t, ts, c.query)
+		for _, w := range c.want {
+			if !strings.Contains(body, w) {
+				t.Errorf("%s: missing %s in %s", c.query, w, body)
+			}
+		}
+	}
+	if body := get(t, ts, "verb=ListSets"); strings.Contains(body, "label:DE-15") {
+		t.Errorf("ListSets: restricted label DE-15 should not be listed")
+	}
+}
@@ -0,0 +1,126 @@
+// Package openurl builds Z39.88-2004 OpenURL context objects in key/encoded
+// value (KEV) format and COinS, https://www.niso.org/publications/z3988-2004-r2010.
+package openurl
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"io/ioutil"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+const (
+	// Version of the OpenURL standard.
+	Version = "Z39.88-2004"
+
+	// FormatJournal is the KEV metadata format for articles and journals.
+	FormatJournal = "info:ofi/fmt:kev:mtx:journal"
+	// FormatBook is the KEV metadata format for books and book items.
+	FormatBook = "info:ofi/fmt:kev:mtx:book"
+)
+
+// ContextObject describes a referent, the work an OpenURL points to.
+type ContextObject struct {
+	// Format is the referent metadata format, e.g. FormatJournal.
+	Format string
+	// Referrer identifies the source of the link, e.g. info:sid/finc.info:ai.
+	Referrer string
+	// IDs are identifiers of the referent, e.g. info:doi/10.1000/182.
+	IDs []string
+	// Values holds referent metadata, keys without rft. prefix, e.g. atitle.
+	Values url.Values
+}
+
+// New creates an empty context object with a given format.
+func New(format string) *ContextObject {
+	return &ContextObject{Format: format, Values: make(url.Values)}
+}
+
+// Add adds non-empty values for a referent key, e.g. au.
+func (c *ContextObject) Add(key string, values ...string) {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			c.Values.Add(key, v)
+		}
+	}
+}
+
+// AddID adds a non-empty referent identifier, e.g. info:doi/10.1000/182.
+func (c *ContextObject) AddID(id string) {
+	if id != "" {
+		c.IDs = append(c.IDs, id)
+	}
+}
+
+// KEV returns the context object as key/encoded value string. Keys are
+// ordered: version and format first, then identifiers and sorted metadata.
+func (c *ContextObject) KEV() string {
+	var parts []string
+	add := func(key, value string) {
+		parts = append(parts, url.QueryEscape(key)+"="+url.QueryEscape(value))
+	}
+	add("url_ver", Version)
+	add("ctx_ver", Version)
+	add("ctx_enc", "info:ofi/enc:UTF-8")
+	if c.Referrer != "" {
+		add("rfr_id", c.Referrer)
+	}
+	add("rft_val_fmt", c.Format)
+	for _, id := range c.IDs {
+		add("rft_id", id)
+	}
+	var keys []string
+	for k := range c.Values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		for _, v := range c.Values[k] {
+			add("rft."+k, v)
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// URL returns an OpenURL for a link resolver base URL, which may contain a
+// query already.
+func (c *ContextObject) URL(base string) string {
+	switch {
+	case base == "":
+		return c.KEV()
+	case strings.HasSuffix(base, "?") || strings.HasSuffix(base, "&"):
+		return base + c.KEV()
+	case strings.Contains(base, "?"):
+		return base + "&" + c.KEV()
+	}
+	return base + "?" + c.KEV()
+}
+
+// COinS returns the context object embedded in an HTML span, which
+// browser extensions and reference managers pick up.
+func (c *ContextObject) COinS() string {
+	return fmt.Sprintf(`<span class="Z3988" title="%s"></span>`, html.EscapeString(c.KEV()))
+}
+
+// Resolvers maps ISIL to link resolver base URLs. The empty key holds an
+// optional default.
+type Resolvers map[string]string
+
+// LoadResolvers reads resolvers from a JSON file, e.g.
+//
+//     {"DE-15": "https://katalog.ub.uni-leipzig.de/openurl", "": "https://sfx.example.org/sfx"}
+//
+func LoadResolvers(filename string) (Resolvers, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var r Resolvers
+	if err := json.Unmarshal(b, &r); err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+	return r, nil
+}
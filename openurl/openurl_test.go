@@ -0,0 +1,69 @@
+package openurl
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestKEV(t *testing.T) {
+	c := New(FormatJournal)
+	c.Referrer = "info:sid/finc.info:ai"
+	c.AddID("info:doi/10.1/x y")
+	c.AddID("")
+	c.Add("jtitle", "Journal of A & B")
+	c.Add("atitle", "Title", " ")
+	c.Add("au", "Doe, J", "Roe, R")
+
+	want := "url_ver=Z39.88-2004&ctx_ver=Z39.88-2004&ctx_enc=info%3Aofi%2Fenc%3AUTF-8" +
+		"&rfr_id=info%3Asid%2Ffinc.info%3Aai&rft_val_fmt=info%3Aofi%2Ffmt%3Akev%3Amtx%3Ajournal" +
+		"&rft_id=info%3Adoi%2F10.1%2Fx+y&rft.atitle=Title&rft.au=Doe%2C+J&rft.au=Roe%2C+R" +
+		"&rft.jtitle=Journal+of+A+%26+B"
+	if got := c.KEV(); got != want {
+		t.Errorf("KEV: got %s, want %s", got, want)
+	}
+
+	var cases = []struct {
+		base string
+		want string
+	}{
+		{"", want},
+		{"http://sfx.example.org/sfx", "http://sfx.example.org/sfx?" + want},
+		{"http://sfx.example.org/sfx?", "http://sfx.example.org/sfx?" + want},
+		{"http://sfx.example.org/sfx?sid=x", "http://sfx.example.org/sfx?sid=x&" + want},
+	}
+	for _, tc := range cases {
+		if got := c.URL(tc.base); got != tc.want {
+			t.Errorf("URL(%q): got %s, want %s", tc.base, got, tc.want)
+		}
+	}
+
+	coins := `<span class="Z3988" title="url_ver=Z39.88-2004&amp;ctx_ver=`
+	if got := c.COinS(); got[:len(coins)] != coins {
+		t.Errorf("COinS: got %s", got)
+	}
+}
+
+func TestLoadResolvers(t *testing.T) {
+	f, err := ioutil.TempFile("", "span-resolvers-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	io.WriteString(f, `{"DE-15": "http://a", "": "http://default"}`)
+	f.Close()
+	r, err := LoadResolvers(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r["DE-15"] != "http://a" || r[""] != "http://default" {
+		t.Errorf("got %v", r)
+	}
+	if err := ioutil.WriteFile(f.Name(), []byte("[]"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadResolvers(f.Name()); err == nil {
+		t.Errorf("expected error for invalid resolvers file")
+	}
+}
@@ -24,11 +24,14 @@ Library data tools.
 mkdir -p $RPM_BUILD_ROOT/usr/sbin
 install -m 755 span-check $RPM_BUILD_ROOT/usr/sbin
 install -m 755 span-compare $RPM_BUILD_ROOT/usr/sbin
+install -m 755 span-es $RPM_BUILD_ROOT/usr/sbin
 install -m 755 span-export $RPM_BUILD_ROOT/usr/sbin
 install -m 755 span-freeze $RPM_BUILD_ROOT/usr/sbin
+install -m 755 span-harvest $RPM_BUILD_ROOT/usr/sbin
 install -m 755 span-import $RPM_BUILD_ROOT/usr/sbin
 install -m 755 span-local-data $RPM_BUILD_ROOT/usr/sbin
 install -m 755 span-oa-filter $RPM_BUILD_ROOT/usr/sbin
+install -m 755 span-oai-server $RPM_BUILD_ROOT/usr/sbin
 install -m 755 span-redact $RPM_BUILD_ROOT/usr/sbin
 install -m 755 span-report $RPM_BUILD_ROOT/usr/sbin
 install -m 755 span-review $RPM_BUILD_ROOT/usr/sbin
@@ -57,11 +60,14 @@ rm -rf %{_topdir}/BUILD/%{name}
 
 /usr/sbin/span-check
 /usr/sbin/span-compare
+/usr/sbin/span-es
 /usr/sbin/span-export
 /usr/sbin/span-freeze
+/usr/sbin/span-harvest
 /usr/sbin/span-import
 /usr/sbin/span-local-data
 /usr/sbin/span-oa-filter
+/usr/sbin/span-oai-server
 /usr/sbin/span-redact
 /usr/sbin/span-report
 /usr/sbin/span-review
@@ -167,10 +167,10 @@ func (p *Processor) Run() error {
 
 	for {
 		b, err := br.ReadBytes(p.RecordSeparator)
-		if err == io.EOF {
+		if err == io.EOF && len(b) == 0 {
 			break
 		}
-		if err != nil {
+		if err != nil && err != io.EOF {
 			return err
 		}
 		if len(bytes.TrimSpace(b)) == 0 && p.SkipEmptyLines {
@@ -186,6 +186,10 @@ func (p *Processor) Run() error {
 			batch.Reset()
 		}
 		i++
+		if err == io.EOF {
+			// Last record without separator.
+			break
+		}
 	}
 
 	queue <- batch.Slice()
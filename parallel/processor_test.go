@@ -104,6 +104,15 @@ func TestSimple(t *testing.T) {
 			},
 			err: nil,
 		},
+		{
+			about:    `The last record does not need a separator.`,
+			r:        strings.NewReader("a\nb"),
+			expected: "A\nB\n",
+			f: func(_ int64, b []byte) ([]byte, error) {
+				return append(bytes.ToUpper(bytes.TrimSpace(b)), '\n'), nil
+			},
+			err: nil,
+		},
 	}
 
 	for _, c := range cases {
@@ -0,0 +1,243 @@
+package span
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrorPolicy decides, what happens to a conversion, if a record cannot be
+// converted for reasons other than a Skip.
+type ErrorPolicy string
+
+const (
+	// PolicyFail stops at the first error.
+	PolicyFail ErrorPolicy = "fail"
+	// PolicySkip drops records with errors and continues.
+	PolicySkip ErrorPolicy = "skip"
+	// PolicyCollect drops records with errors and continues, but the whole
+	// run is considered failed, if any error occurred.
+	PolicyCollect ErrorPolicy = "collect"
+)
+
+// ParseErrorPolicy returns the policy for a name.
+func ParseErrorPolicy(s string) (ErrorPolicy, error) {
+	switch p := ErrorPolicy(s); p {
+	case PolicyFail, PolicySkip, PolicyCollect:
+		return p, nil
+	}
+	return "", fmt.Errorf("unknown error policy: %s (want fail, skip or collect)", s)
+}
+
+// ReportSample is an example for a skipped or failed record.
+type ReportSample struct {
+	ID string `json:"id,omitempty"`
+	// Input is the file or archive member, the record came from.
+	Input string `json:"input,omitempty"`
+	// Offset in the input, a byte offset or a line number, depending on the
+	// input, -1 if unknown.
+	Offset  int64  `json:"offset"`
+	Message string `json:"message"`
+}
+
+// ReportEntry counts occurrences of a skip reason or error class.
+type ReportEntry struct {
+	Count   int64          `json:"count"`
+	Samples []ReportSample `json:"samples,omitempty"`
+}
+
+// Report keeps track of converted, skipped and failed records. It is safe for
+// concurrent use.
+type Report struct {
+	mu sync.Mutex
+
+	Format     string                  `json:"format"`
+	Policy     ErrorPolicy             `json:"policy"`
+	OffsetUnit string                  `json:"offset_unit,omitempty"`
+	Started    time.Time               `json:"started"`
+	Finished   time.Time               `json:"finished"`
+	Converted  int64                   `json:"converted"`
+	SkipCount  int64                   `json:"skipped_total"`
+	ErrorCount int64                   `json:"errors_total"`
+	Skipped    map[string]*ReportEntry `json:"skipped"`
+	Errors     map[string]*ReportEntry `json:"errors"`
+
+	// SampleSize limits the number of samples kept per reason or class.
+	SampleSize int `json:"-"`
+
+	input string // Current input.
+}
+
+// NewReport starts a new report for a format.
+func NewReport(format string, policy ErrorPolicy) *Report {
+	return &Report{
+		Format:     format,
+		Policy:     policy,
+		Started:    time.Now(),
+		Skipped:    make(map[string]*ReportEntry),
+		Errors:     make(map[string]*ReportEntry),
+		SampleSize: 10,
+	}
+}
+
+// SkipReason returns the first word of the reason, e.g. NO_JTITLE for a
+// reason like "NO_JTITLE ai-49-...".
+func SkipReason(s Skip) string {
+	fields := strings.Fields(s.Reason)
+	if len(fields) == 0 {
+		return "UNKNOWN"
+	}
+	return fields[0]
+}
+
+// Classifier is implemented by errors, that know their class, e.g.
+// INVALID_RECORD. Converters can use it to group errors in a report.
+type Classifier interface {
+	Class() string
+}
+
+// ClassError attaches a class to an error.
+type ClassError struct {
+	Name string
+	Err  error
+}
+
+// Class returns the class name.
+func (e *ClassError) Class() string { return e.Name }
+
+func (e *ClassError) Error() string { return e.Err.Error() }
+
+// Unwrap returns the underlying error.
+func (e *ClassError) Unwrap() error { return e.Err }
+
+var (
+	quotedPattern   = regexp.MustCompile(`"[^"]*"|'[^']*'|` + "`[^`]*`")
+	nonAlnumPattern = regexp.MustCompile(`[^A-Z0-9]+`)
+)
+
+// ErrorClass returns a class name for an error, used as key in a report. The
+// class of the first Classifier in the chain wins, a few common types of
+// the standard library have fixed classes. Otherwise the class is derived
+// from the message prefix, before the first colon and without quoted
+// values, e.g. "cannot find identifier: x" becomes CANNOT_FIND_IDENTIFIER.
+func ErrorClass(err error) string {
+	var (
+		c  Classifier
+		se *json.SyntaxError
+		te *json.UnmarshalTypeError
+		pe *time.ParseError
+		ne *strconv.NumError
+	)
+	switch {
+	case errors.As(err, &c):
+		return c.Class()
+	case errors.As(err, &se):
+		return "INVALID_JSON"
+	case errors.As(err, &te):
+		return "INVALID_JSON_TYPE"
+	case errors.As(err, &pe):
+		return "INVALID_DATE"
+	case errors.As(err, &ne):
+		return "INVALID_NUMBER"
+	}
+	msg := quotedPattern.ReplaceAllString(err.Error(), " ")
+	if i := strings.Index(msg, ":"); i > 0 {
+		msg = msg[:i]
+	}
+	fields := strings.Fields(msg)
+	if len(fields) > 6 {
+		fields = fields[:6]
+	}
+	class := strings.Trim(nonAlnumPattern.ReplaceAllString(strings.ToUpper(strings.Join(fields, " ")), "_"), "_")
+	if class == "" {
+		return "UNKNOWN"
+	}
+	return class
+}
+
+// SetInput sets the name of the file or archive member currently processed.
+func (r *Report) SetInput(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.input = name
+}
+
+// Success counts a converted record.
+func (r *Report) Success() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Converted++
+}
+
+// Add records a skip or an error for a record with a given id (may be empty)
+// and input offset. It returns the error, if the conversion should stop
+// according to the policy, nil otherwise. Skips never stop a conversion.
+func (r *Report) Add(err error, id string, offset int64) error {
+	if err == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var (
+		entries map[string]*ReportEntry
+		key     string
+		result  error
+	)
+	switch e := err.(type) {
+	case Skip:
+		entries, key = r.Skipped, SkipReason(e)
+		r.SkipCount++
+	default:
+		entries, key = r.Errors, ErrorClass(err)
+		r.ErrorCount++
+		if r.Policy == PolicyFail || r.Policy == "" {
+			result = err
+		}
+	}
+	entry, ok := entries[key]
+	if !ok {
+		entry = &ReportEntry{}
+		entries[key] = entry
+	}
+	entry.Count++
+	if len(entry.Samples) < r.SampleSize {
+		entry.Samples = append(entry.Samples, ReportSample{ID: id, Input: r.input, Offset: offset, Message: err.Error()})
+	}
+	return result
+}
+
+// Failed returns true, if the run should be considered failed.
+func (r *Report) Failed() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.ErrorCount > 0 && r.Policy != PolicySkip
+}
+
+// WriteTo writes the report as JSON.
+func (r *Report) WriteTo(w io.Writer) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.Finished.IsZero() {
+		r.Finished = time.Now()
+	}
+	b, err := json.MarshalIndent(r, "", "    ")
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(append(b, '\n'))
+	return int64(n), err
+}
+
+// String returns a one line summary.
+func (r *Report) String() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return fmt.Sprintf("%s: %d converted, %d skipped, %d errors",
+		r.Format, r.Converted, r.SkipCount, r.ErrorCount)
+}
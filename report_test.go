@@ -0,0 +1,89 @@
+package span
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestReport(t *testing.T) {
+	var tests = []struct {
+		policy  ErrorPolicy
+		stops   bool
+		failed  bool
+		samples int
+	}{
+		{PolicyFail, true, true, 1},
+		{PolicySkip, false, false, 1},
+		{PolicyCollect, false, true, 1},
+	}
+	for _, c := range tests {
+		r := NewReport("dummy", c.policy)
+		r.Success()
+		for i := 0; i < 3; i++ {
+			if err := r.Add(Skip{Reason: "NO_JTITLE ai-1-x"}, "ai-1-x", int64(i)); err != nil {
+				t.Errorf("%s: skip should not stop conversion: %v", c.policy, err)
+			}
+		}
+		err := r.Add(errors.New("broken"), "", 42)
+		if (err != nil) != c.stops {
+			t.Errorf("%s: got %v, want stop=%v", c.policy, err, c.stops)
+		}
+		if r.Failed() != c.failed {
+			t.Errorf("%s: Failed got %v, want %v", c.policy, r.Failed(), c.failed)
+		}
+		if r.SkipCount != 3 || r.Skipped["NO_JTITLE"].Count != 3 {
+			t.Errorf("%s: got %d skipped, want 3", c.policy, r.SkipCount)
+		}
+		entry := r.Errors["BROKEN"]
+		if entry == nil || len(entry.Samples) != c.samples || entry.Samples[0].Offset != 42 {
+			t.Errorf("%s: unexpected error entry: %+v", c.policy, entry)
+		}
+		var buf bytes.Buffer
+		if _, err := r.WriteTo(&buf); err != nil {
+			t.Fatal(err)
+		}
+		var v map[string]interface{}
+		if err := json.Unmarshal(buf.Bytes(), &v); err != nil {
+			t.Errorf("report is not valid JSON: %v", err)
+		}
+	}
+}
+
+func TestErrorClass(t *testing.T) {
+	_, perr := time.Parse("2006-01-02", "2019-13")
+	_, nerr := strconv.Atoi("x")
+	var tests = []struct {
+		err  error
+		want string
+	}{
+		{errors.New("broken"), "BROKEN"},
+		{fmt.Errorf("cannot find identifier: %s", "oai:x:1"), "CANNOT_FIND_IDENTIFIER"},
+		{fmt.Errorf("unknown id type: %s", "pmid"), "UNKNOWN_ID_TYPE"},
+		{fmt.Errorf("invalid ISO 2709 record: short record at offset %d", 10), "INVALID_ISO_2709_RECORD"},
+		{errors.New(`unhandled "x" value`), "UNHANDLED_VALUE"},
+		{errors.New(": "), "UNKNOWN"},
+		{fmt.Errorf("record 1: %w", perr), "INVALID_DATE"},
+		{nerr, "INVALID_NUMBER"},
+		{json.Unmarshal([]byte("{"), new(interface{})), "INVALID_JSON"},
+		{fmt.Errorf("x: %w", &ClassError{Name: "NO_DOI", Err: errors.New("missing doi")}), "NO_DOI"},
+	}
+	for _, c := range tests {
+		if got := ErrorClass(c.err); got != c.want {
+			t.Errorf("ErrorClass(%v): got %s, want %s", c.err, got, c.want)
+		}
+	}
+}
+
+func TestParseErrorPolicy(t *testing.T) {
+	if p, err := ParseErrorPolicy("collect"); err != nil || p != PolicyCollect {
+		t.Errorf("got %v, %v", p, err)
+	}
+	if _, err := ParseErrorPolicy("ignore"); err == nil {
+		t.Errorf("expected error for unknown policy")
+	}
+}